@@ -0,0 +1,138 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// ErrClustersDiffer is returned by compare --exit-code when the clusters
+// differ, for main to exit with 2 without printing it as an error.
+var ErrClustersDiffer = errors.New("the clusters differ")
+
+type CompareOptions struct {
+	view *ViewWebhookOptions
+
+	contexts []string
+	exitCode bool
+}
+
+// NewCompareOptions provides an instance of CompareOptions with default values
+func NewCompareOptions(o *ViewWebhookOptions) *CompareOptions {
+	return &CompareOptions{
+		view: o,
+	}
+}
+
+// NewCmdCompare provides a cobra command wrapping CompareOptions
+func NewCmdCompare(o *ViewWebhookOptions) *cobra.Command {
+	c := NewCompareOptions(o)
+
+	cmd := &cobra.Command{
+		Use:   "compare --context <a> --context <b> [webhook]",
+		Short: "Compare the webhook configurations of two clusters",
+		Long: `Pair the webhook configurations of two kubeconfig contexts by name and report
+semantic differences in rules, selectors, policies, timeouts, service targets and
+CABundle issuers. CABundle bytes and object metadata such as resourceVersion are
+ignored, since they differ between clusters by design.`,
+		Example: fmt.Sprintf(`
+%[1]s view-webhook compare --context staging --context prod
+%[1]s view-webhook compare --context staging --context prod --exit-code
+`, "kubectl"),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.args = args
+			if err := o.loadConfig(cmd); err != nil {
+				return err
+			}
+
+			if err := o.completeKubeconfig(cmd); err != nil {
+				return err
+			}
+
+			if err := c.Validate(); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			err := c.Run()
+			if err == ErrClustersDiffer {
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+			}
+			return err
+		},
+	}
+
+	// shadows the persistent --context of the kubeconfig flags, to be
+	// given once for each side of the comparison
+	cmd.Flags().StringArrayVar(&c.contexts, "context", c.contexts, "The kubeconfig context to compare, given once for each of the two clusters")
+	cmd.Flags().BoolVar(&c.exitCode, "exit-code", c.exitCode, "Exit with 2 when the clusters differ and 1 on errors")
+
+	return cmd
+}
+
+// Validate ensures that exactly two contexts are given
+func (c *CompareOptions) Validate() error {
+	if len(c.contexts) != 2 {
+		return errors.New("--context must be given exactly twice")
+	}
+	return nil
+}
+
+// Run prints the differences of the second context from the first one
+func (c *CompareOptions) Run() error {
+	old, err := c.model(c.contexts[0])
+	if err != nil {
+		return err
+	}
+	new, err := c.model(c.contexts[1])
+	if err != nil {
+		return err
+	}
+
+	changes := snapshot.Diff(old.Model, new.Model, snapshot.Without(snapshot.DiffFields, "caBundle"))
+	if c.view.printOptions.Format != "json" && c.view.printOptions.Format != "yaml" {
+		fmt.Fprintf(c.view.Out, "--- %s\n+++ %s\n", c.contexts[0], c.contexts[1])
+	}
+	if err := printChanges(c.view.Out, c.view.printOptions.Format, changes); err != nil {
+		return err
+	}
+
+	if c.exitCode && len(changes) > 0 {
+		return ErrClustersDiffer
+	}
+	return nil
+}
+
+func (c *CompareOptions) model(context string) (*snapshot.Snapshot, error) {
+	config, err := c.view.RESTConfig(context)
+	if err != nil {
+		return nil, fmt.Errorf("context %s: %v", context, err)
+	}
+
+	model, configurations, err := c.view.ModelFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("context %s: %v", context, err)
+	}
+	return snapshot.New(model, configurations), nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/snapshot"
+	"github.com/spf13/cobra"
+	"io"
+	"sigs.k8s.io/yaml"
+)
+
+// clusterTarget is the diff argument standing for the live cluster
+const clusterTarget = "cluster"
+
+type DiffOptions struct {
+	view *ViewWebhookOptions
+
+	ignoreCABundle bool
+}
+
+// NewDiffOptions provides an instance of DiffOptions with default values
+func NewDiffOptions(o *ViewWebhookOptions) *DiffOptions {
+	return &DiffOptions{
+		view: o,
+	}
+}
+
+// NewCmdDiff provides a cobra command wrapping DiffOptions
+func NewCmdDiff(o *ViewWebhookOptions) *cobra.Command {
+	d := NewDiffOptions(o)
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> [<new>|cluster]",
+		Short: "Show how webhook configurations changed between two snapshots",
+		Long: `Compare a saved snapshot against another snapshot or the live cluster and report
+added and removed webhooks, and changed rules, selectors, failure policies,
+timeouts, CABundle fingerprints and service targets.`,
+		Example: fmt.Sprintf(`
+%[1]s view-webhook diff before.json
+%[1]s view-webhook diff before.json after.json -o json
+`, "kubectl"),
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(c *cobra.Command, args []string) error {
+			old, err := snapshot.Load(args[0])
+			if err != nil {
+				return err
+			}
+
+			var model *printer.PrintModel
+			if len(args) == 2 && args[1] != clusterTarget {
+				s, err := snapshot.Load(args[1])
+				if err != nil {
+					return err
+				}
+				model = s.Model
+			} else {
+				// the live cluster is limited to what the snapshot holds
+				var filter []string
+				if old.Filter != "" {
+					filter = []string{old.Filter}
+				}
+				if err := o.Complete(c, filter); err != nil {
+					return err
+				}
+				if err := o.Validate(); err != nil {
+					return err
+				}
+				if model, _, err = o.Model(); err != nil {
+					return err
+				}
+			}
+
+			return d.Run(old.Model, model)
+		},
+	}
+
+	cmd.Flags().BoolVar(&d.ignoreCABundle, "ignore-ca-bundle", d.ignoreCABundle, "Do not report changed CABundle fingerprints, e.g. after a certificate rotation")
+
+	return cmd
+}
+
+// Run prints the changes from the old to the new model
+func (d *DiffOptions) Run(old, new *printer.PrintModel) error {
+	fields := snapshot.DiffFields
+	if d.ignoreCABundle {
+		fields = snapshot.Without(fields, "caBundle")
+	}
+
+	return printChanges(d.view.Out, d.view.printOptions.Format, snapshot.Diff(old, new, fields))
+}
+
+// printChanges writes the given changes as json, yaml or text
+func printChanges(out io.Writer, format string, changes []snapshot.Change) error {
+	if changes == nil {
+		changes = []snapshot.Change{}
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	default:
+		return snapshot.WriteChanges(out, changes)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/tui"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"os"
+	"sort"
+)
+
+// RunInteractive browses the webhooks in a terminal UI until it is quit.
+func (o *ViewWebhookOptions) RunInteractive() error {
+	in, inOK := o.In.(*os.File)
+	out, outOK := o.Out.(*os.File)
+	if !inOK || !outOK {
+		return errors.New("the interactive mode needs a terminal")
+	}
+
+	return tui.NewBrowser(in, out, o.loadState, o.refresh, o.printOptions).Run()
+}
+
+// loadState loads the webhooks together with the namespaces and API
+// resources of the cluster for the terminal UI.
+func (o *ViewWebhookOptions) loadState() (*tui.State, error) {
+	model, configurations, err := o.Model()
+	if err != nil {
+		return nil, err
+	}
+	state := &tui.State{Model: model, Configurations: configurations, Warnings: append([]string(nil), configurations.Warnings...)}
+
+	clientSet, err := kubernetes.NewForConfig(o.restConfig)
+	if err != nil {
+		return nil, err
+	}
+	if list, err := clientSet.CoreV1().Namespaces().List(context.Background(), metaV1.ListOptions{}); err == nil {
+		for _, ns := range list.Items {
+			state.Namespaces = append(state.Namespaces, ns.Name)
+		}
+	} else {
+		// without the permission to list namespaces, the active ones are shown
+		active := map[string]bool{}
+		for _, item := range model.Items {
+			for _, ns := range item.ActiveNamespaces {
+				active[ns] = true
+			}
+		}
+		for ns := range active {
+			state.Namespaces = append(state.Namespaces, ns)
+		}
+		sort.Strings(state.Namespaces)
+	}
+
+	// the resources Model discovered, the resource view is empty when the
+	// cluster could not be discovered
+	state.Resources = o.resources
+	if o.discoveryErr != nil {
+		state.Warnings = append(state.Warnings, fmt.Sprintf("resources are not shown: %v", o.discoveryErr))
+	}
+	return state, nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	authenticationV1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"text/tabwriter"
+)
+
+type MatchOptions struct {
+	view *ViewWebhookOptions
+
+	filename    string
+	oldFilename string
+	operation   string
+	user        string
+	groups      []string
+}
+
+// NewMatchOptions provides an instance of MatchOptions with default values
+func NewMatchOptions(o *ViewWebhookOptions) *MatchOptions {
+	return &MatchOptions{
+		view:      o,
+		operation: "CREATE",
+	}
+}
+
+// NewCmdMatch provides a cobra command wrapping MatchOptions
+func NewCmdMatch(o *ViewWebhookOptions) *cobra.Command {
+	m := NewMatchOptions(o)
+
+	cmd := &cobra.Command{
+		Use:   "match -f <object> [webhook]",
+		Short: "Resolve which webhooks and policies apply to an object",
+		Long: `Resolve which webhooks and policies an admission request for the given object is
+sent to, checking rules, namespace and object selectors, and evaluating the CEL
+matchConditions against the object, the old object and the request. The user of
+the request is only known when given with --request-user and --request-group.`,
+		Example: fmt.Sprintf(`
+%[1]s view-webhook match -f deployment.yaml
+%[1]s view-webhook match -f deployment.yaml --old-object old.yaml --operation UPDATE
+%[1]s view-webhook match -f deployment.yaml --request-user jane --request-group system:authenticated
+`, "kubectl"),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := m.Validate(); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return m.Run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&m.filename, "filename", "f", m.filename, "Manifest of the object of the request")
+	cmd.Flags().StringVar(&m.oldFilename, "old-object", m.oldFilename, "Manifest of the existing object of an UPDATE or DELETE request")
+	cmd.Flags().StringVar(&m.operation, "operation", m.operation, "Operation of the request, one of CREATE, UPDATE, DELETE or CONNECT")
+	cmd.Flags().StringVar(&m.user, "request-user", m.user, "Username of the user making the request, request.userInfo.username in matchConditions")
+	cmd.Flags().StringArrayVar(&m.groups, "request-group", m.groups, "Group of the user making the request, request.userInfo.groups in matchConditions, can be repeated")
+
+	return cmd
+}
+
+// Validate ensures that an object is given with a known operation
+func (m *MatchOptions) Validate() error {
+	if m.filename == "" {
+		return errors.New("an object must be given with -f")
+	}
+	m.operation = strings.ToUpper(m.operation)
+	switch m.operation {
+	case "CREATE", "UPDATE", "DELETE", "CONNECT":
+		return nil
+	}
+	return fmt.Errorf("unknown operation %q, must be one of CREATE, UPDATE, DELETE or CONNECT", m.operation)
+}
+
+// Run prints which webhooks and policies apply to the object
+func (m *MatchOptions) Run() error {
+	object, err := readObject(m.filename)
+	if err != nil {
+		return err
+	}
+	req := match.Request{
+		Operation: m.operation,
+		Object:    object,
+		Kind:      object.GroupVersionKind(),
+		UserInfo:  authenticationV1.UserInfo{Username: m.user, Groups: m.groups},
+	}
+	if m.oldFilename != "" {
+		if req.OldObject, err = readObject(m.oldFilename); err != nil {
+			return err
+		}
+	}
+
+	dc, err := discovery.NewDiscoveryClientForConfig(m.view.restConfig)
+	if err != nil {
+		return err
+	}
+	groups, err := restmapper.GetAPIGroupResources(dc)
+	if err != nil {
+		return err
+	}
+	mapping, err := restmapper.NewDiscoveryRESTMapper(groups).RESTMapping(req.Kind.GroupKind(), req.Kind.Version)
+	if err != nil {
+		return err
+	}
+	req.Resource = mapping.Resource
+	req.Namespaced = mapping.Scope.Name() == meta.RESTScopeNameNamespace
+
+	if req.Namespaced {
+		if object.GetNamespace() == "" {
+			namespace, _, err := m.view.configFlags.ToRawKubeConfigLoader().Namespace()
+			if err != nil {
+				return err
+			}
+			object.SetNamespace(namespace)
+		}
+
+		clientSet, err := kubernetes.NewForConfig(m.view.restConfig)
+		if err != nil {
+			return err
+		}
+		// a namespace that does not exist yet leaves namespaceObject null
+		ns, err := clientSet.CoreV1().Namespaces().Get(context.Background(), object.GetNamespace(), metaV1.GetOptions{})
+		if err == nil {
+			data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ns)
+			if err != nil {
+				return err
+			}
+			req.NamespaceObject = &unstructured.Unstructured{Object: data}
+		}
+	}
+
+	model, _, err := m.view.Model()
+	if err != nil {
+		return err
+	}
+	req.Equivalents = match.EquivalentResources(req.Resource, m.view.resources)
+	return m.print(match.Match(model, req))
+}
+
+func (m *MatchOptions) print(results []match.Result) error {
+	out := m.view.Out
+	switch m.view.printOptions.Format {
+	case "json":
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tWEBHOOK\tAPPLIES\tREASON")
+	for _, r := range results {
+		applies := "no"
+		if r.Matched {
+			applies = "yes"
+		} else if r.Unknown {
+			applies = "unknown"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Kind, r.Name, r.Webhook, applies, r.Reason)
+	}
+	return w.Flush()
+}
+
+// readObject reads a YAML or JSON manifest of a single object.
+func readObject(path string) (*unstructured.Unstructured, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	object := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &object.Object); err != nil {
+		return nil, fmt.Errorf("invalid object %s: %v", path, err)
+	}
+	if object.GetKind() == "" || object.GetAPIVersion() == "" {
+		return nil, fmt.Errorf("invalid object %s: apiVersion and kind are required", path)
+	}
+	return object, nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"github.com/spf13/cobra"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type NamespaceOptions struct {
+	view *ViewWebhookOptions
+
+	namespace     string
+	allNamespaces bool
+}
+
+// NewNamespaceOptions provides an instance of NamespaceOptions with default values
+func NewNamespaceOptions(o *ViewWebhookOptions) *NamespaceOptions {
+	return &NamespaceOptions{
+		view: o,
+	}
+}
+
+// NewCmdNamespace provides a cobra command wrapping NamespaceOptions
+func NewCmdNamespace(o *ViewWebhookOptions) *cobra.Command {
+	n := NewNamespaceOptions(o)
+
+	cmd := &cobra.Command{
+		Use:   "ns <namespace> | --all-namespaces",
+		Short: "List the webhooks and policies intercepting a namespace",
+		Long: `List the webhooks and policies whose namespace selectors match a namespace, with
+the rules and operations they intercept in it. With --all-namespaces a matrix of
+every namespace against every webhook is shown instead.`,
+		Example: fmt.Sprintf(`
+%[1]s view-webhook ns default
+%[1]s view-webhook ns --all-namespaces
+`, "kubectl"),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				n.namespace = args[0]
+			}
+
+			if err := n.Validate(); err != nil {
+				return err
+			}
+
+			if err := o.Complete(c, nil); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return n.Run()
+		},
+	}
+
+	cmd.Flags().BoolVarP(&n.allNamespaces, "all-namespaces", "A", n.allNamespaces, "Show every namespace against every webhook")
+
+	return cmd
+}
+
+// Validate ensures that either a namespace or --all-namespaces is given
+func (n *NamespaceOptions) Validate() error {
+	if n.namespace == "" && !n.allNamespaces {
+		return errors.New("a namespace or --all-namespaces must be given")
+	}
+	if n.namespace != "" && n.allNamespaces {
+		return errors.New("a namespace cannot be given with --all-namespaces")
+	}
+	return nil
+}
+
+// Run prints the webhooks and policies intercepting the namespaces
+func (n *NamespaceOptions) Run() error {
+	clientSet, err := kubernetes.NewForConfig(n.view.restConfig)
+	if err != nil {
+		return err
+	}
+
+	namespaces := []string{n.namespace}
+	if n.allNamespaces {
+		list, err := clientSet.CoreV1().Namespaces().List(context.Background(), metaV1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		namespaces = nil
+		for _, ns := range list.Items {
+			namespaces = append(namespaces, ns.Name)
+		}
+	} else if _, err := clientSet.CoreV1().Namespaces().Get(context.Background(), n.namespace, metaV1.GetOptions{}); apiErrors.IsNotFound(err) {
+		// other errors, such as not being allowed to get it, still show
+		// what can be told about the namespace
+		return fmt.Errorf("namespace %q not found", n.namespace)
+	}
+
+	model, _, err := n.view.Model()
+	if err != nil {
+		return err
+	}
+
+	p := printer.NewPrinter(n.view.Out, n.view.printOptions)
+	return p.PrintNamespaces(printer.NewNamespaceModel(model, namespaces), n.allNamespaces)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"github.com/spf13/cobra"
+)
+
+type ResourcesOptions struct {
+	view *ViewWebhookOptions
+
+	subresources bool
+	uncovered    bool
+}
+
+// NewResourcesOptions provides an instance of ResourcesOptions with default values
+func NewResourcesOptions(o *ViewWebhookOptions) *ResourcesOptions {
+	return &ResourcesOptions{
+		view: o,
+	}
+}
+
+// NewCmdResources provides a cobra command wrapping ResourcesOptions
+func NewCmdResources(o *ViewWebhookOptions) *cobra.Command {
+	r := NewResourcesOptions(o)
+
+	cmd := &cobra.Command{
+		Use:   "resources",
+		Short: "List the webhooks and policies intercepting every API resource",
+		Long: `Discover every API resource the cluster serves and list, for each resource and
+operation, the mutating and validating webhooks and policies whose rules intercept
+it. Wildcards and matchPolicy Equivalent are resolved the way the API server does,
+namespace and object selectors are not taken into account.`,
+		Example: fmt.Sprintf(`
+%[1]s view-webhook resources
+%[1]s view-webhook resources --uncovered
+%[1]s view-webhook resources --subresources -o json
+`, "kubectl"),
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, nil); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			return r.Run()
+		},
+	}
+
+	cmd.Flags().BoolVar(&r.subresources, "subresources", r.subresources, "Include subresources such as pods/exec and deployments/scale")
+	cmd.Flags().BoolVar(&r.uncovered, "uncovered", r.uncovered, "Only list resources and operations no validating webhook or policy intercepts")
+
+	return cmd
+}
+
+// Run prints the webhooks and policies intercepting every API resource
+func (r *ResourcesOptions) Run() error {
+	model, _, err := r.view.Model()
+	if err != nil {
+		return err
+	}
+	if r.view.discoveryErr != nil {
+		return r.view.discoveryErr
+	}
+
+	resourceModel := match.Resources(model, r.view.resources, r.subresources)
+	if r.uncovered {
+		var items []printer.PrintResourceItem
+		for _, item := range resourceModel.Items {
+			if len(item.Validating) == 0 {
+				items = append(items, item)
+			}
+		}
+		resourceModel.Items = items
+	}
+
+	p := printer.NewPrinter(r.view.Out, r.view.printOptions)
+	return p.PrintResources(resourceModel)
+}
@@ -19,16 +19,23 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/config"
 	"github.com/Trendyol/kubectl-view-webhook/pkg/k8s"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/lint"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
 	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 type ViewWebhookOptions struct {
@@ -38,14 +45,45 @@ type ViewWebhookOptions struct {
 	kubeconfig string
 	args       []string
 
+	configFile       string
+	printOptions     *printer.Options
+	certWarningDays  int
+	certCriticalDays int
+	probe            bool
+	probeTimeout     time.Duration
+	readSecrets      bool
+	interactive      bool
+	refresh          time.Duration
+	events           bool
+	eventsSince      time.Duration
+	lintConfig       *lint.Config
+
+	// resources are the API resources discovered by the last ModelFor, for
+	// the commands that need them too, nil with discoveryErr when the
+	// cluster could not be discovered. failedGroups are the group versions
+	// that failed discovery.
+	resources    []printer.APIResource
+	failedGroups []schema.GroupVersion
+	discoveryErr error
+
 	genericclioptions.IOStreams
 }
 
 // NewViewWebhookOptions provides an instance of ViewWebhookOptions with default values
 func NewViewWebhookOptions(streams genericclioptions.IOStreams) *ViewWebhookOptions {
+	printOptions := printer.NewOptions()
+
 	return &ViewWebhookOptions{
-		configFlags: genericclioptions.NewConfigFlags(true),
-		IOStreams:   streams,
+		configFlags:      genericclioptions.NewConfigFlags(true),
+		configFile:       config.DefaultPath(),
+		printOptions:     printOptions,
+		certWarningDays:  int(printOptions.CertWarning.Hours() / 24),
+		certCriticalDays: int(printOptions.CertCritical.Hours() / 24),
+		probeTimeout:     5 * time.Second,
+		refresh:          30 * time.Second,
+		eventsSince:      time.Hour,
+		lintConfig:       lint.NewConfig(),
+		IOStreams:        streams,
 	}
 }
 
@@ -62,11 +100,16 @@ func NewCmdViewWebhook(streams genericclioptions.IOStreams, version, commit, dat
 `, "kubectl"),
 		SilenceErrors: false,
 		SilenceUsage:  false,
+		Args:          cobra.MaximumNArgs(1),
 		RunE: func(c *cobra.Command, args []string) error {
 			if err := o.Complete(c, args); err != nil {
 				return err
 			}
 
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
 			if err := o.Run(); err != nil {
 				return err
 			}
@@ -81,7 +124,36 @@ Date: %s`, version, commit, date)
 		}(version, commit, date),
 	}
 
-	o.configFlags.AddFlags(cmd.Flags())
+	flags := cmd.PersistentFlags()
+	o.configFlags.AddFlags(flags)
+	flags.StringVar(&o.configFile, "config", o.configFile, "Path to the view-webhook configuration file")
+	flags.StringVarP(&o.printOptions.Format, "output", "o", o.printOptions.Format, fmt.Sprintf("Output format, one of %v, or one of %v followed by =TEMPLATE", printer.Formats, printer.TemplateFormats))
+	flags.BoolVar(&o.printOptions.NoHeaders, "no-headers", o.printOptions.NoHeaders, "Omit the header row of the custom-columns, csv and tsv output")
+	flags.StringSliceVar(&o.printOptions.Columns, "columns", o.printOptions.Columns, fmt.Sprintf("Columns of the table output, any of %v", printer.Columns))
+	flags.BoolVar(&o.printOptions.NoColor, "no-color", o.printOptions.NoColor, "Disable colours, also disabled by NO_COLOR or when the output is not a terminal")
+	flags.BoolVar(&o.printOptions.ASCII, "ascii", o.printOptions.ASCII, "Draw trees and marks with ASCII characters only")
+	flags.BoolVar(&o.printOptions.ListExpanded, "list-expanded", o.printOptions.ListExpanded, "List the served resources wildcard rules expand to, not only their count")
+	flags.IntVar(&o.certWarningDays, "cert-warning-days", o.certWarningDays, "Remaining CABundle lifetime in days below which it is shown as warning")
+	flags.IntVar(&o.certCriticalDays, "cert-critical-days", o.certCriticalDays, "Remaining CABundle lifetime in days below which it is shown as critical")
+	flags.BoolVar(&o.probe, "probe", o.probe, "Perform a TLS handshake to each webhook endpoint and verify the served certificate against its CABundle")
+	flags.DurationVar(&o.probeTimeout, "probe-timeout", o.probeTimeout, "Timeout of each TLS probe")
+	flags.BoolVar(&o.readSecrets, "read-secrets", o.readSecrets, "Read the TLS secrets mounted by the pods of each webhook service and the secrets cert-manager injects CABundles from, and verify them against the CABundle")
+	flags.BoolVar(&o.events, "events", o.events, "Count the recent events of admission requests each webhook failed or denied")
+	flags.DurationVar(&o.eventsSince, "events-since", o.eventsSince, "How far back events are counted by --events")
+	flags.StringSliceVar(&o.lintConfig.SensitiveResources, "sensitive-resources", o.lintConfig.SensitiveResources, "Resources, as resource.group, reported when a webhook intercepts them")
+	flags.StringSliceVar(&o.lintConfig.SystemNamespaces, "system-namespaces", o.lintConfig.SystemNamespaces, "Namespaces reported when a webhook intercepts requests in them")
+	flags.StringSliceVar(&o.lintConfig.Disabled, "disable-checks", o.lintConfig.Disabled, "Names of the lint checks that are not run")
+
+	cmd.Flags().BoolVarP(&o.interactive, "interactive", "i", o.interactive, "Browse the webhooks in a terminal UI")
+	cmd.Flags().DurationVar(&o.refresh, "refresh", o.refresh, "Interval the terminal UI is refreshed at, 0 to only refresh on demand")
+
+	cmd.AddCommand(NewCmdServeFake(streams))
+	cmd.AddCommand(NewCmdSnapshot(o))
+	cmd.AddCommand(NewCmdDiff(o))
+	cmd.AddCommand(NewCmdCompare(o))
+	cmd.AddCommand(NewCmdMatch(o))
+	cmd.AddCommand(NewCmdNamespace(o))
+	cmd.AddCommand(NewCmdResources(o))
 
 	return cmd
 }
@@ -90,6 +162,26 @@ Date: %s`, version, commit, date)
 func (o *ViewWebhookOptions) Complete(cmd *cobra.Command, args []string) error {
 	o.args = args
 
+	if err := o.loadConfig(cmd); err != nil {
+		return err
+	}
+
+	if err := o.completeKubeconfig(cmd); err != nil {
+		return err
+	}
+
+	config, err := o.RESTConfig(*o.configFlags.Context)
+	if err != nil {
+		fmt.Printf("The kubeconfig cannot be loaded: %v\n", err)
+		os.Exit(1)
+	}
+
+	o.restConfig = config
+	return nil
+}
+
+// completeKubeconfig resolves the path of the kubeconfig file
+func (o *ViewWebhookOptions) completeKubeconfig(cmd *cobra.Command) error {
 	kubeconfig, err := cmd.Flags().GetString("kubeconfig")
 	if err != nil {
 		return err
@@ -102,15 +194,69 @@ func (o *ViewWebhookOptions) Complete(cmd *cobra.Command, args []string) error {
 			kubeconfig = envvar
 		}
 	}
+	o.kubeconfig = kubeconfig
+	return nil
+}
+
+// RESTConfig loads the client configuration of the given kubeconfig
+// context, the current context when empty
+func (o *ViewWebhookOptions) RESTConfig(context string) (*rest.Config, error) {
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: o.kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: context},
+	).ClientConfig()
+}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+// loadConfig fills every option whose flag was not given from the
+// configuration file.
+func (o *ViewWebhookOptions) loadConfig(cmd *cobra.Command) error {
+	cfg, err := config.Load(o.configFile)
 	if err != nil {
-		fmt.Printf("The kubeconfig cannot be loaded: %v\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	o.restConfig = config
-	o.kubeconfig = kubeconfig
+	flags := cmd.Flags()
+	if !flags.Changed("output") && cfg.Output != "" {
+		o.printOptions.Format = cfg.Output
+	}
+	if !flags.Changed("columns") && len(cfg.Columns) > 0 {
+		o.printOptions.Columns = cfg.Columns
+	}
+	if !flags.Changed("no-color") && cfg.NoColor {
+		o.printOptions.NoColor = true
+	}
+	if !flags.Changed("ascii") && cfg.ASCII {
+		o.printOptions.ASCII = true
+	}
+	if cfg.Colors != nil {
+		if cfg.Colors.OK != "" {
+			o.printOptions.Colors.OK = cfg.Colors.OK
+		}
+		if cfg.Colors.Warning != "" {
+			o.printOptions.Colors.Warning = cfg.Colors.Warning
+		}
+		if cfg.Colors.Critical != "" {
+			o.printOptions.Colors.Critical = cfg.Colors.Critical
+		}
+	}
+	if !flags.Changed("cert-warning-days") && cfg.Certificates.WarningDays > 0 {
+		o.certWarningDays = cfg.Certificates.WarningDays
+	}
+	if !flags.Changed("cert-critical-days") && cfg.Certificates.CriticalDays > 0 {
+		o.certCriticalDays = cfg.Certificates.CriticalDays
+	}
+	if !flags.Changed("disable-checks") && len(cfg.Lint.Disabled) > 0 {
+		o.lintConfig.Disabled = cfg.Lint.Disabled
+	}
+	if !flags.Changed("sensitive-resources") && len(cfg.Lint.SensitiveResources) > 0 {
+		o.lintConfig.SensitiveResources = cfg.Lint.SensitiveResources
+	}
+	if !flags.Changed("system-namespaces") && len(cfg.Lint.SystemNamespaces) > 0 {
+		o.lintConfig.SystemNamespaces = cfg.Lint.SystemNamespaces
+	}
+
+	o.printOptions.CertWarning = time.Duration(o.certWarningDays) * 24 * time.Hour
+	o.printOptions.CertCritical = time.Duration(o.certCriticalDays) * 24 * time.Hour
 	return nil
 }
 
@@ -119,30 +265,92 @@ func (o *ViewWebhookOptions) Validate() error {
 	if len(o.args) > 2 {
 		return errors.New("more than one argument supplied , you can only give one argument for the webhook name")
 	}
-	return nil
+	if o.refresh < 0 {
+		return errors.New("the refresh interval cannot be negative")
+	}
+	if o.eventsSince <= 0 {
+		return errors.New("--events-since must be positive")
+	}
+	if err := o.lintConfig.Validate(); err != nil {
+		return err
+	}
+	return o.printOptions.Validate()
 }
 
 // Run lists all available webhooks on a user's KUBECONFIG or updates the
 // current context based on a provided namespace.
 func (o *ViewWebhookOptions) Run() error {
-	p := printer.NewPrinter(o.Out)
+	if o.interactive {
+		return o.RunInteractive()
+	}
 
-	// create the ClientSet from restConfig
-	clientSet, err := kubernetes.NewForConfig(o.restConfig)
+	p := printer.NewPrinter(o.Out, o.printOptions)
+
+	model, _, err := o.Model()
 	if err != nil {
 		return err
 	}
 
-	//validatingWebhookClient := clientset.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations()
+	return p.Print(model)
+}
+
+// Model fetches the webhook configurations selected by the arguments
+// and returns them analysed and linted, together with the raw ones.
+func (o *ViewWebhookOptions) Model() (*printer.PrintModel, *k8s.Configurations, error) {
+	return o.ModelFor(o.restConfig)
+}
+
+// ModelFor is Model against the cluster of the given client configuration.
+func (o *ViewWebhookOptions) ModelFor(config *rest.Config) (*printer.PrintModel, *k8s.Configurations, error) {
+	// create the ClientSet from config
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	mw := k8s.NewWebHookClient(clientSet)
-	model, err := mw.Run(o.args)
+	mw.SetDynamicClient(dynamicClient)
+	// events are only listed in detail views, which always count failures
+	detailed := o.interactive || o.printOptions.Format == "describe"
+	mw.SetEvents(detailed)
+	if o.events || detailed {
+		mw.SetFailures(o.eventsSince)
+	}
+	if o.probe {
+		mw.SetProber(k8s.NewProber(clientSet, config, o.probeTimeout))
+	}
+	mw.SetSecrets(o.readSecrets)
 
+	configurations, err := mw.Fetch(o.args)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	// the terminal UI shows the warnings itself
+	if !o.interactive {
+		for _, warning := range configurations.Warnings {
+			fmt.Fprintf(o.ErrOut, "Warning: %s\n", warning)
+		}
 	}
 
-	p.Print(model)
+	model := mw.Build(configurations)
+	// clusters denying discovery are shown with their wildcards unexpanded
+	// and their rules unchecked
+	o.resources, o.failedGroups, o.discoveryErr = k8s.DiscoverResources(discoveryClient)
+	if o.discoveryErr == nil {
+		match.Expand(model, o.resources)
+		match.CheckServed(model, o.resources, o.failedGroups)
+	}
+	lint.NewLinter(o.lintConfig.Checks()...).Run(model)
 
-	return nil
+	return model, configurations, nil
 }
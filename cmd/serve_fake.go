@@ -0,0 +1,140 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/fakewebhook"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+type ServeFakeOptions struct {
+	server        *fakewebhook.Options
+	configuration *fakewebhook.ConfigurationOptions
+
+	mode       string
+	configFile string
+
+	genericclioptions.IOStreams
+}
+
+// NewServeFakeOptions provides an instance of ServeFakeOptions with default values
+func NewServeFakeOptions(streams genericclioptions.IOStreams) *ServeFakeOptions {
+	return &ServeFakeOptions{
+		server:        fakewebhook.NewOptions(),
+		configuration: fakewebhook.NewConfigurationOptions(),
+		mode:          string(fakewebhook.ModeAllow),
+		IOStreams:     streams,
+	}
+}
+
+// NewCmdServeFake provides a cobra command wrapping ServeFakeOptions
+func NewCmdServeFake(streams genericclioptions.IOStreams) *cobra.Command {
+	o := NewServeFakeOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "serve-fake [flags]",
+		Short: "Serve a local HTTPS admission webhook for testing",
+		Long: `Serve a local HTTPS admission webhook with a generated CA and print a matching
+Mutating/ValidatingWebhookConfiguration that points at it through a url clientConfig.`,
+		Example: fmt.Sprintf(`
+%[1]s view-webhook serve-fake --mode deny
+%[1]s view-webhook serve-fake --kind Mutating --mode patch --config-file fake.yaml
+`, "kubectl"),
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			mode, err := fakewebhook.ParseMode(o.mode)
+			if err != nil {
+				return err
+			}
+			o.server.Mode = mode
+			if err := o.Validate(); err != nil {
+				return err
+			}
+			return o.Run()
+		},
+	}
+
+	cmd.Flags().StringVar(&o.server.Addr, "addr", o.server.Addr, "Address to listen on, port 0 picks a free port")
+	cmd.Flags().StringVar(&o.server.Host, "host", o.server.Host, "Host name used in the serving certificate and the clientConfig url")
+	cmd.Flags().StringVar(&o.server.Path, "path", o.server.Path, "HTTP path the admission handler is served on")
+	cmd.Flags().StringVar(&o.mode, "mode", o.mode, fmt.Sprintf("How to answer admission requests, one of %v", fakewebhook.Modes))
+	cmd.Flags().StringVar(&o.server.Message, "message", o.server.Message, "Message returned on deny and error")
+	cmd.Flags().StringVar(&o.server.Patch, "patch", o.server.Patch, "JSON patch returned in patch mode")
+	cmd.Flags().DurationVar(&o.server.Delay, "delay", o.server.Delay, "Delay before answering in sleep mode")
+	cmd.Flags().IntVar(&o.server.StatusCode, "status-code", o.server.StatusCode, "HTTP status code returned in error mode")
+	cmd.Flags().DurationVar(&o.server.CertValidity, "cert-validity", o.server.CertValidity, "Lifetime of the generated certificates")
+
+	cmd.Flags().StringVar(&o.configuration.Kind, "kind", o.configuration.Kind, "Kind of the printed configuration, Mutating or Validating")
+	cmd.Flags().StringVar(&o.configuration.Name, "name", o.configuration.Name, "Name of the printed configuration")
+	cmd.Flags().StringSliceVar(&o.configuration.Resources, "resources", o.configuration.Resources, "Resources the printed configuration intercepts")
+	cmd.Flags().StringSliceVar(&o.configuration.Operations, "operations", o.configuration.Operations, "Operations the printed configuration intercepts")
+	cmd.Flags().StringVar(&o.configuration.FailurePolicy, "failure-policy", o.configuration.FailurePolicy, "Failure policy of the printed configuration, Fail or Ignore")
+	cmd.Flags().Int32Var(&o.configuration.TimeoutSeconds, "timeout", o.configuration.TimeoutSeconds, "Timeout in seconds of the printed configuration")
+	cmd.Flags().StringVar(&o.configFile, "config-file", o.configFile, "Write the configuration to this file instead of stdout")
+
+	return cmd
+}
+
+// Validate checks the printed configuration is valid before the server
+// starts listening.
+func (o *ServeFakeOptions) Validate() error {
+	return o.configuration.Validate()
+}
+
+// Run starts the fake webhook, prints its configuration and serves
+// until interrupted.
+func (o *ServeFakeOptions) Run() error {
+	server, err := fakewebhook.NewServer(*o.server)
+	if err != nil {
+		return err
+	}
+
+	config, err := server.ConfigurationYAML(*o.configuration)
+	if err != nil {
+		_ = server.Close()
+		return err
+	}
+
+	if o.configFile != "" {
+		if err := ioutil.WriteFile(o.configFile, config, 0644); err != nil {
+			_ = server.Close()
+			return err
+		}
+	} else {
+		fmt.Fprintf(o.Out, "%s", config)
+	}
+	fmt.Fprintf(o.ErrOut, "Serving fake %s webhook in %q mode on %s\n", o.configuration.Kind, o.server.Mode, server.URL())
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+
+	return server.Serve()
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/snapshot"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdSnapshot provides a cobra command saving snapshots of the
+// analysed webhook configurations
+func NewCmdSnapshot(o *ViewWebhookOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Persist the analysed webhook configurations",
+	}
+
+	save := &cobra.Command{
+		Use:   "save <file> [webhook]",
+		Short: "Save the analysed model and raw configurations to a file",
+		Long: `Save the analysed model and the raw webhook configurations to a file, as YAML
+when its extension is .yaml or .yml and as JSON otherwise.`,
+		Example: fmt.Sprintf(`
+%[1]s view-webhook snapshot save before.json
+`, "kubectl"),
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := o.Complete(c, args[1:]); err != nil {
+				return err
+			}
+
+			if err := o.Validate(); err != nil {
+				return err
+			}
+
+			model, configurations, err := o.Model()
+			if err != nil {
+				return err
+			}
+
+			s := snapshot.New(model, configurations)
+			if len(args) == 2 {
+				s.Filter = args[1]
+			}
+			if err := s.Save(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(o.ErrOut, "Saved %d webhooks to %s\n", len(model.Items), args[0])
+			return nil
+		},
+	}
+
+	cmd.AddCommand(save)
+	return cmd
+}
@@ -33,7 +33,12 @@ func main() {
 	pflag.CommandLine = flags
 
 	root := cmd.NewCmdViewWebhook(genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}, version, commit, date)
-	if err := root.Execute(); err != nil {
+	switch err := root.Execute(); {
+	case err == cmd.ErrClustersDiffer:
+		// compare --exit-code found differences, which is not a failure
+		// to print but has to be told apart from one, like diff(1) does
+		os.Exit(2)
+	case err != nil:
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,81 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"io/ioutil"
+	"k8s.io/client-go/util/homedir"
+	"os"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+)
+
+// Config holds the defaults read from the configuration file. Every field
+// is optional and command line flags take precedence over it.
+type Config struct {
+	Output       string               `json:"output,omitempty"`
+	Columns      []string             `json:"columns,omitempty"`
+	Colors       *printer.ColorScheme `json:"colors,omitempty"`
+	NoColor      bool                 `json:"noColor,omitempty"`
+	ASCII        bool                 `json:"ascii,omitempty"`
+	Certificates Certificates         `json:"certificates,omitempty"`
+	Lint         Lint                 `json:"lint,omitempty"`
+}
+
+// Certificates holds the remaining lifetime thresholds of CABundles.
+type Certificates struct {
+	WarningDays  int `json:"warningDays,omitempty"`
+	CriticalDays int `json:"criticalDays,omitempty"`
+}
+
+// Lint holds the settings of the lint checks.
+type Lint struct {
+	Disabled           []string `json:"disabled,omitempty"`
+	SensitiveResources []string `json:"sensitiveResources,omitempty"`
+	SystemNamespaces   []string `json:"systemNamespaces,omitempty"`
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/kubectl-view-webhook/config.yaml,
+// falling back to ~/.config when XDG_CONFIG_HOME is not set.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		dir = filepath.Join(homedir.HomeDir(), ".config")
+	}
+	return filepath.Join(dir, "kubectl-view-webhook", "config.yaml")
+}
+
+// Load reads the configuration file at the given path. A missing file is
+// only an error when it is not the default one.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && path == DefaultPath() {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %v", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		want    *Config
+		err     string
+	}{
+		{
+			name: "every section",
+			content: `output: json
+columns: [kind, name]
+certificates:
+  warningDays: 30
+  criticalDays: 7
+lint:
+  disabled: [self-interception]
+  systemNamespaces: [kube-system]
+`,
+			want: &Config{
+				Output:       "json",
+				Columns:      []string{"kind", "name"},
+				Certificates: Certificates{WarningDays: 30, CriticalDays: 7},
+				Lint: Lint{
+					Disabled:         []string{"self-interception"},
+					SystemNamespaces: []string{"kube-system"},
+				},
+			},
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    &Config{},
+		},
+		{
+			name:    "unknown field",
+			content: "outptu: json\n",
+			err:     `unknown field "outptu"`,
+		},
+		{
+			name:    "wrong type",
+			content: "certificates:\n  warningDays: soon\n",
+			err:     "invalid configuration file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.Replace(tt.name, " ", "-", -1)+".yaml")
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := Load(path)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("got %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := Load(DefaultPath())
+	if err != nil || !reflect.DeepEqual(cfg, &Config{}) {
+		t.Errorf("default path: got %+v, %v, want an empty configuration", cfg, err)
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "config.yaml")); !os.IsNotExist(err) {
+		t.Errorf("explicit path: error = %v, want a missing file", err)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakewebhook
+
+import (
+	"fmt"
+	admissionV1 "k8s.io/api/admissionregistration/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// ConfigurationOptions describes the webhook configuration generated for
+// a running Server.
+type ConfigurationOptions struct {
+	// Kind is either "Mutating" or "Validating".
+	Kind           string
+	Name           string
+	Resources      []string
+	Operations     []string
+	FailurePolicy  string
+	TimeoutSeconds int32
+}
+
+// NewConfigurationOptions provides an instance of ConfigurationOptions with default values
+func NewConfigurationOptions() *ConfigurationOptions {
+	return &ConfigurationOptions{
+		Kind:           "Validating",
+		Name:           "fake-webhook",
+		Resources:      []string{"configmaps"},
+		Operations:     []string{"CREATE", "UPDATE"},
+		FailurePolicy:  string(admissionV1.Fail),
+		TimeoutSeconds: 10,
+	}
+}
+
+// Validate tells whether the options describe a configuration the API
+// server accepts.
+func (o *ConfigurationOptions) Validate() error {
+	switch strings.ToLower(o.Kind) {
+	case "mutating", "validating":
+	default:
+		return fmt.Errorf("unknown webhook kind %q, must be Mutating or Validating", o.Kind)
+	}
+	if o.Name == "" {
+		return fmt.Errorf("configuration name must not be empty")
+	}
+	if len(o.Resources) == 0 {
+		return fmt.Errorf("at least one resource must be given")
+	}
+	if len(o.Operations) == 0 {
+		return fmt.Errorf("at least one operation must be given")
+	}
+	for _, op := range o.Operations {
+		switch admissionV1.OperationType(strings.ToUpper(op)) {
+		case admissionV1.OperationAll, admissionV1.Create, admissionV1.Update, admissionV1.Delete, admissionV1.Connect:
+		default:
+			return fmt.Errorf("unknown operation %q, must be one of CREATE, UPDATE, DELETE, CONNECT or *", op)
+		}
+	}
+	switch admissionV1.FailurePolicyType(o.FailurePolicy) {
+	case admissionV1.Fail, admissionV1.Ignore:
+	default:
+		return fmt.Errorf("unknown failure policy %q, must be Fail or Ignore", o.FailurePolicy)
+	}
+	if o.TimeoutSeconds < 1 || o.TimeoutSeconds > 30 {
+		return fmt.Errorf("timeout must be between 1 and 30 seconds, got %d", o.TimeoutSeconds)
+	}
+	return nil
+}
+
+// Configuration returns a Mutating/ValidatingWebhookConfiguration pointing
+// at the Server through a `url` clientConfig together with its CABundle.
+func (s *Server) Configuration(o ConfigurationOptions) (runtime.Object, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+
+	url := s.URL()
+	failurePolicy := admissionV1.FailurePolicyType(o.FailurePolicy)
+	sideEffects := admissionV1.SideEffectClassNone
+	timeout := o.TimeoutSeconds
+	webhookName := o.Name + ".kubectl-view-webhook.local"
+
+	var ops []admissionV1.OperationType
+	for _, op := range o.Operations {
+		ops = append(ops, admissionV1.OperationType(strings.ToUpper(op)))
+	}
+	rules := []admissionV1.RuleWithOperations{{
+		Operations: ops,
+		Rule: admissionV1.Rule{
+			APIGroups:   []string{"*"},
+			APIVersions: []string{"*"},
+			Resources:   o.Resources,
+		},
+	}}
+	clientConfig := admissionV1.WebhookClientConfig{
+		URL:      &url,
+		CABundle: s.CABundle(),
+	}
+	admissionReviewVersions := []string{"v1"}
+
+	switch strings.ToLower(o.Kind) {
+	case "mutating":
+		return &admissionV1.MutatingWebhookConfiguration{
+			TypeMeta:   metaV1.TypeMeta{APIVersion: admissionV1.SchemeGroupVersion.String(), Kind: "MutatingWebhookConfiguration"},
+			ObjectMeta: metaV1.ObjectMeta{Name: o.Name},
+			Webhooks: []admissionV1.MutatingWebhook{{
+				Name:                    webhookName,
+				ClientConfig:            clientConfig,
+				Rules:                   rules,
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          &timeout,
+				AdmissionReviewVersions: admissionReviewVersions,
+			}},
+		}, nil
+	default:
+		return &admissionV1.ValidatingWebhookConfiguration{
+			TypeMeta:   metaV1.TypeMeta{APIVersion: admissionV1.SchemeGroupVersion.String(), Kind: "ValidatingWebhookConfiguration"},
+			ObjectMeta: metaV1.ObjectMeta{Name: o.Name},
+			Webhooks: []admissionV1.ValidatingWebhook{{
+				Name:                    webhookName,
+				ClientConfig:            clientConfig,
+				Rules:                   rules,
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				TimeoutSeconds:          &timeout,
+				AdmissionReviewVersions: admissionReviewVersions,
+			}},
+		}, nil
+	}
+}
+
+// ConfigurationYAML renders Configuration as YAML, ready for `kubectl apply -f -`.
+func (s *Server) ConfigurationYAML(o ConfigurationOptions) ([]byte, error) {
+	obj, err := s.Configuration(o)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(obj)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakewebhook
+
+import (
+	admissionV1 "k8s.io/api/admissionregistration/v1"
+	"strings"
+	"testing"
+)
+
+func TestConfigurationOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(o *ConfigurationOptions)
+		err    string
+	}{
+		{name: "defaults", modify: func(o *ConfigurationOptions) {}},
+		{name: "lower case kind", modify: func(o *ConfigurationOptions) { o.Kind = "mutating" }},
+		{name: "unknown kind", modify: func(o *ConfigurationOptions) { o.Kind = "Auditing" }, err: "unknown webhook kind"},
+		{name: "no resources", modify: func(o *ConfigurationOptions) { o.Resources = nil }, err: "resource"},
+		{name: "unknown operation", modify: func(o *ConfigurationOptions) { o.Operations = []string{"PATCH"} }, err: "unknown operation"},
+		{name: "unknown failure policy", modify: func(o *ConfigurationOptions) { o.FailurePolicy = "Retry" }, err: "unknown failure policy"},
+		{name: "timeout too long", modify: func(o *ConfigurationOptions) { o.TimeoutSeconds = 31 }, err: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewConfigurationOptions()
+			tt.modify(o)
+			err := o.Validate()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("unexpected error %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("error = %v, want it to contain %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestConfiguration(t *testing.T) {
+	server := startServer(t, *NewOptions())
+
+	o := NewConfigurationOptions()
+	o.Kind = "Mutating"
+	obj, err := server.Configuration(*o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configuration, ok := obj.(*admissionV1.MutatingWebhookConfiguration)
+	if !ok {
+		t.Fatalf("got %T, want a v1 MutatingWebhookConfiguration", obj)
+	}
+	webhook := configuration.Webhooks[0]
+	if *webhook.ClientConfig.URL != server.URL() || string(webhook.ClientConfig.CABundle) != string(server.CABundle()) {
+		t.Errorf("client config does not point at the server: %+v", webhook.ClientConfig)
+	}
+	if got := strings.Join(webhook.AdmissionReviewVersions, ","); got != "v1" {
+		t.Errorf("admission review versions = %s, want v1", got)
+	}
+}
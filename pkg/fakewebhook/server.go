@@ -0,0 +1,265 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakewebhook
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	admissionV1 "k8s.io/api/admission/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Mode decides how the fake webhook answers admission requests.
+type Mode string
+
+const (
+	ModeAllow Mode = "allow"
+	ModeDeny  Mode = "deny"
+	ModePatch Mode = "patch"
+	ModeSleep Mode = "sleep"
+	ModeError Mode = "error"
+)
+
+// Modes lists every supported Mode.
+var Modes = []Mode{ModeAllow, ModeDeny, ModePatch, ModeSleep, ModeError}
+
+// ParseMode converts the given string into a Mode.
+func ParseMode(s string) (Mode, error) {
+	for _, m := range Modes {
+		if strings.EqualFold(s, string(m)) {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown mode %q, must be one of %v", s, Modes)
+}
+
+// Options configures a fake webhook Server.
+type Options struct {
+	// Addr is the listen address, e.g. "127.0.0.1:8443". Port 0 picks a free port.
+	Addr string
+	// Host is the name the serving certificate is issued for and the
+	// generated clientConfig.url points at.
+	Host string
+	// Path is the HTTP path the admission handler is served on.
+	Path string
+	Mode Mode
+	// Message is returned as the status message on deny and error.
+	Message string
+	// Patch is a JSON patch returned in patch mode. When empty an
+	// annotation marking the object as patched is added.
+	Patch string
+	// Delay is how long the server sleeps before answering in sleep mode.
+	Delay time.Duration
+	// StatusCode is the HTTP status returned in error mode.
+	StatusCode int
+	// CertValidity is the lifetime of the generated CA and serving certificate.
+	CertValidity time.Duration
+}
+
+// NewOptions provides an instance of Options with default values
+func NewOptions() *Options {
+	return &Options{
+		Addr:         "127.0.0.1:0",
+		Host:         "127.0.0.1",
+		Path:         "/admit",
+		Mode:         ModeAllow,
+		Message:      "denied by kubectl-view-webhook fake server",
+		Delay:        15 * time.Second,
+		StatusCode:   http.StatusInternalServerError,
+		CertValidity: 24 * time.Hour,
+	}
+}
+
+// Server is a local HTTPS admission webhook with a generated CA.
+type Server struct {
+	opts     Options
+	certs    *certBundle
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewServer generates certificates and binds the listener, but does not
+// start serving until Serve is called.
+func NewServer(opts Options) (*Server, error) {
+	if _, err := ParseMode(string(opts.Mode)); err != nil {
+		return nil, err
+	}
+
+	hosts := []string{opts.Host}
+	if opts.Host != "localhost" {
+		hosts = append(hosts, "localhost")
+	}
+	certs, err := generateCertBundle(hosts, opts.CertValidity)
+	if err != nil {
+		return nil, fmt.Errorf("generating certificates: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		opts:     opts,
+		certs:    certs,
+		listener: listener,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(opts.Path, s.handleAdmission)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	s.server = &http.Server{
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{certs.serverCert},
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+	return s, nil
+}
+
+// Serve serves admission requests until Shutdown is called.
+func (s *Server) Serve() error {
+	err := s.server.ServeTLS(s.listener, "", "")
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// Shutdown gracefully stops the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// Close stops listening without serving, for a Server whose Serve was
+// never called.
+func (s *Server) Close() error {
+	return s.listener.Close()
+}
+
+// URL returns the address admission requests should be sent to.
+func (s *Server) URL() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return fmt.Sprintf("https://%s%s", net.JoinHostPort(s.opts.Host, port), s.opts.Path)
+}
+
+// CABundle returns the PEM encoded CA the serving certificate is signed by.
+func (s *Server) CABundle() []byte {
+	return s.certs.caPEM
+}
+
+func (s *Server) handleAdmission(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionV1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "malformed AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	switch s.opts.Mode {
+	case ModeError:
+		http.Error(w, s.opts.Message, s.opts.StatusCode)
+		return
+	case ModeSleep:
+		select {
+		case <-time.After(s.opts.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	response := &admissionV1.AdmissionResponse{
+		UID:     review.Request.UID,
+		Allowed: true,
+	}
+
+	switch s.opts.Mode {
+	case ModeDeny:
+		response.Allowed = false
+		response.Result = &metaV1.Status{
+			Status:  metaV1.StatusFailure,
+			Message: s.opts.Message,
+			Reason:  metaV1.StatusReasonForbidden,
+			Code:    http.StatusForbidden,
+		}
+	case ModePatch:
+		patch, err := s.buildPatch(review.Request.Object.Raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		patchType := admissionV1.PatchTypeJSONPatch
+		response.Patch = patch
+		response.PatchType = &patchType
+	}
+
+	// answer with the same apiVersion the API server asked with
+	review.Request = nil
+	review.Response = response
+	if review.APIVersion == "" {
+		review.APIVersion = admissionV1.SchemeGroupVersion.String()
+		review.Kind = "AdmissionReview"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(review)
+}
+
+// buildPatch returns the configured JSON patch, or one that annotates the
+// reviewed object when no patch was configured.
+func (s *Server) buildPatch(object []byte) ([]byte, error) {
+	if s.opts.Patch != "" {
+		var ops []interface{}
+		if err := json.Unmarshal([]byte(s.opts.Patch), &ops); err != nil {
+			return nil, fmt.Errorf("invalid JSON patch: %v", err)
+		}
+		return []byte(s.opts.Patch), nil
+	}
+
+	var obj struct {
+		Metadata struct {
+			Annotations map[string]string `json:"annotations"`
+		} `json:"metadata"`
+	}
+	_ = json.Unmarshal(object, &obj)
+
+	const key = "kubectl-view-webhook/patched"
+	if obj.Metadata.Annotations == nil {
+		return json.Marshal([]map[string]interface{}{
+			{"op": "add", "path": "/metadata/annotations", "value": map[string]string{key: "true"}},
+		})
+	}
+	return json.Marshal([]map[string]interface{}{
+		{"op": "add", "path": "/metadata/annotations/" + strings.Replace(key, "/", "~1", -1), "value": "true"},
+	})
+}
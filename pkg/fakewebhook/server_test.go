@@ -0,0 +1,144 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakewebhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	admissionV1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// startServer serves a fake webhook with the given options until the test ends.
+func startServer(t *testing.T, opts Options) *Server {
+	t.Helper()
+	server, err := NewServer(opts)
+	if err != nil {
+		t.Fatalf("starting fake webhook: %v", err)
+	}
+	go server.Serve()
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+	})
+	return server
+}
+
+// review sends an AdmissionReview for a configmap to the given server,
+// trusting only its CABundle.
+func review(t *testing.T, server *Server) (*http.Response, *admissionV1.AdmissionReview) {
+	t.Helper()
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(server.CABundle()) {
+		t.Fatal("CABundle holds no certificate")
+	}
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+
+	body, err := json.Marshal(admissionV1.AdmissionReview{
+		Request: &admissionV1.AdmissionRequest{
+			UID:    "42",
+			Object: runtime.RawExtension{Raw: []byte(`{"metadata":{"name":"settings"}}`)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Post(server.URL(), "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	answer := &admissionV1.AdmissionReview{}
+	if err := json.NewDecoder(resp.Body).Decode(answer); err != nil {
+		t.Fatal(err)
+	}
+	return resp, answer
+}
+
+func TestServerModes(t *testing.T) {
+	tests := []struct {
+		mode    Mode
+		patch   string
+		status  int
+		allowed bool
+		want    string
+	}{
+		{mode: ModeAllow, status: http.StatusOK, allowed: true},
+		{mode: ModeDeny, status: http.StatusOK, want: "denied by kubectl-view-webhook fake server"},
+		{mode: ModePatch, status: http.StatusOK, allowed: true,
+			want: `[{"op":"add","path":"/metadata/annotations","value":{"kubectl-view-webhook/patched":"true"}}]`},
+		{mode: ModePatch, patch: `[{"op":"remove","path":"/data"}]`, status: http.StatusOK, allowed: true,
+			want: `[{"op":"remove","path":"/data"}]`},
+		{mode: ModeSleep, status: http.StatusOK, allowed: true},
+		{mode: ModeError, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+tt.patch, func(t *testing.T) {
+			opts := NewOptions()
+			opts.Mode = tt.mode
+			opts.Patch = tt.patch
+			opts.Delay = 10 * time.Millisecond
+			resp, answer := review(t, startServer(t, *opts))
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if answer == nil {
+				return
+			}
+			response := answer.Response
+			if response == nil || response.UID != "42" {
+				t.Fatalf("response = %+v, want one for request 42", response)
+			}
+			if response.Allowed != tt.allowed {
+				t.Errorf("allowed = %v, want %v", response.Allowed, tt.allowed)
+			}
+			switch tt.mode {
+			case ModeDeny:
+				if response.Result == nil || response.Result.Message != tt.want {
+					t.Errorf("result = %+v, want message %q", response.Result, tt.want)
+				}
+			case ModePatch:
+				if string(response.Patch) != tt.want {
+					t.Errorf("patch = %s, want %s", response.Patch, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if m, err := ParseMode("DENY"); err != nil || m != ModeDeny {
+		t.Errorf("ParseMode(DENY) = %q, %v, want deny", m, err)
+	}
+	if _, err := ParseMode("teapot"); err == nil {
+		t.Error("ParseMode(teapot) succeeded, want an error")
+	}
+}
@@ -0,0 +1,267 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+	"time"
+)
+
+const injectCAFromSecretAnnotation = "cert-manager.io/inject-ca-from-secret"
+
+// certificateResources are the cert-manager Certificate versions tried in
+// order, v1alpha2 is still the only one served by older installations.
+var certificateResources = []schema.GroupVersionResource{
+	{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+	{Group: "cert-manager.io", Version: "v1alpha2", Resource: "certificates"},
+}
+
+// caSource is the CA cert-manager injects into a configuration, looked up
+// once per configuration and compared with the CABundle of each webhook.
+type caSource struct {
+	item *printer.PrintCAInjectionItem
+	ca   []byte
+}
+
+// caInjection resolves the cert-manager CA injection annotations of the
+// configuration with the given metadata, nil if it has none.
+func (w *WebHookClient) caInjection(meta metaV1.ObjectMeta) *caSource {
+	if ref := meta.Annotations[injectCAFromAnnotation]; ref != "" {
+		return w.caFromCertificate(ref)
+	}
+	if ref := meta.Annotations[injectCAFromSecretAnnotation]; ref != "" {
+		item := &printer.PrintCAInjectionItem{Secret: ref}
+		ns, name, err := splitRef(ref)
+		if err != nil {
+			item.Status = printer.CAInjectionError
+			item.Error = err.Error()
+			return &caSource{item: item}
+		}
+		return w.caFromSecret(item, ns, name)
+	}
+	return nil
+}
+
+func (w *WebHookClient) caFromCertificate(ref string) *caSource {
+	item := &printer.PrintCAInjectionItem{Certificate: ref}
+	source := &caSource{item: item}
+
+	ns, name, err := splitRef(ref)
+	if err != nil {
+		item.Status = printer.CAInjectionError
+		item.Error = err.Error()
+		return source
+	}
+	if w.dynamic == nil {
+		item.Status = printer.CAInjectionError
+		item.Error = "no dynamic client to read Certificates with"
+		return source
+	}
+
+	gvr, err := w.certificateResource()
+	if err != nil {
+		item.Status = printer.CAInjectionError
+		item.Error = err.Error()
+		return source
+	} else if gvr == nil {
+		item.Status = printer.CAInjectionCertificateMissing
+		item.Error = "cert-manager Certificates are not served by the cluster"
+		return source
+	}
+
+	certificate, err := w.dynamic.Resource(*gvr).Namespace(ns).Get(w.context, name, metaV1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		item.Status = printer.CAInjectionCertificateMissing
+		return source
+	} else if err != nil {
+		item.Status = printer.CAInjectionError
+		item.Error = err.Error()
+		return source
+	}
+
+	item.Ready, item.Renewing = certificateConditions(certificate)
+	if renewal, found, _ := unstructured.NestedString(certificate.Object, "status", "renewalTime"); found {
+		if t, err := time.Parse(time.RFC3339, renewal); err == nil {
+			item.RenewalTime = &t
+			if t.Before(time.Now()) {
+				item.Renewing = true
+			}
+		}
+	}
+
+	secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+	if secretName == "" {
+		item.Status = printer.CAInjectionError
+		item.Error = "certificate has no spec.secretName"
+		return source
+	}
+	item.Secret = ns + "/" + secretName
+	return w.caFromSecret(item, ns, secretName)
+}
+
+// certificateResource returns the newest cert-manager Certificate version
+// the cluster serves, nil when cert-manager is not installed. Discovery
+// is asked once, so that clusters without cert-manager are not sent a
+// request per configuration.
+func (w *WebHookClient) certificateResource() (*schema.GroupVersionResource, error) {
+	if w.certificateDiscovered {
+		return w.certificateGVR, w.certificateErr
+	}
+	w.certificateDiscovered = true
+
+	for _, gvr := range certificateResources {
+		resources, err := w.client.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+		if apiErrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			w.certificateErr = err
+			return nil, err
+		}
+		for _, r := range resources.APIResources {
+			if r.Name == gvr.Resource {
+				gvr := gvr
+				w.certificateGVR = &gvr
+				return w.certificateGVR, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (w *WebHookClient) caFromSecret(item *printer.PrintCAInjectionItem, ns, name string) *caSource {
+	source := &caSource{item: item}
+	if !w.secrets {
+		item.Status = printer.CAInjectionUnchecked
+		return source
+	}
+
+	secret, err := w.client.CoreV1().Secrets(ns).Get(w.context, name, metaV1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		item.Status = printer.CAInjectionSecretMissing
+		return source
+	} else if err != nil {
+		item.Status = printer.CAInjectionError
+		item.Error = err.Error()
+		return source
+	}
+
+	// cert-manager's cainjector injects ca.crt, self-signed issuers may
+	// leave it empty in which case the certificate is its own CA
+	source.ca = secret.Data["ca.crt"]
+	if len(source.ca) == 0 {
+		source.ca = secret.Data["tls.crt"]
+	}
+	if len(source.ca) == 0 {
+		item.Status = printer.CAInjectionError
+		item.Error = "secret has neither ca.crt nor tls.crt"
+		return source
+	}
+
+	if blocks := pemBlocks(secret.Data["tls.crt"]); len(blocks) > 0 {
+		if cert, err := x509.ParseCertificate(blocks[0]); err == nil {
+			item.SecretNotAfter = cert.NotAfter
+		}
+	}
+	return source
+}
+
+// Check compares the injected CA with the given webhook CABundle.
+func (s *caSource) Check(bundle []byte) *printer.PrintCAInjectionItem {
+	item := *s.item
+	if item.Status != "" {
+		return &item
+	}
+
+	if sameCertificates(s.ca, bundle) {
+		item.Status = printer.CAInjectionInSync
+	} else {
+		item.Status = printer.CAInjectionMismatch
+	}
+	return &item
+}
+
+// certificateConditions returns whether the given cert-manager Certificate
+// is Ready and whether it is being (re)issued.
+func certificateConditions(certificate *unstructured.Unstructured) (ready bool, issuing bool) {
+	conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch condition["type"] {
+		case "Ready":
+			ready = condition["status"] == "True"
+		case "Issuing":
+			issuing = condition["status"] == "True"
+		}
+	}
+	return ready, issuing
+}
+
+// sameCertificates reports whether the given PEM bundles hold the same
+// certificates, regardless of their order and surrounding text.
+func sameCertificates(a, b []byte) bool {
+	as, bs := pemBlocks(a), pemBlocks(b)
+	if len(as) == 0 || len(as) != len(bs) {
+		return false
+	}
+	for _, x := range as {
+		found := false
+		for _, y := range bs {
+			if bytes.Equal(x, y) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func pemBlocks(data []byte) [][]byte {
+	var blocks [][]byte
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return blocks
+		}
+		if block.Type == "CERTIFICATE" {
+			blocks = append(blocks, block.Bytes)
+		}
+	}
+}
+
+// splitRef splits a "namespace/name" reference.
+func splitRef(ref string) (string, string, error) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid reference %q, must be namespace/name", ref)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,310 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicFake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testCA is a certificate authority issuing certificates in tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	ca := &testCA{}
+	ca.cert, ca.key, ca.pem = issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: name},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	return ca
+}
+
+// issue returns a serving certificate for the given DNS names signed by the CA.
+func (ca *testCA) issue(t *testing.T, dnsNames ...string) []byte {
+	t.Helper()
+	_, _, pemBytes := issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:    dnsNames,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	return pemBytes
+}
+
+// issue signs the given template with the given CA, or itself when nil.
+func issue(t *testing.T, template *x509.Certificate, ca *testCA) (*x509.Certificate, *ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(24 * time.Hour)
+
+	parent, signer := template, key
+	if ca != nil {
+		parent, signer = ca.cert, ca.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func certificate(name, secretName string, ready bool, renewal time.Time) *unstructured.Unstructured {
+	status := "False"
+	if ready {
+		status = "True"
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": name, "namespace": "webhooks"},
+		"spec":       map[string]interface{}{"secretName": secretName},
+		"status": map[string]interface{}{
+			"conditions":  []interface{}{map[string]interface{}{"type": "Ready", "status": status}},
+			"renewalTime": renewal.UTC().Format(time.RFC3339),
+		},
+	}}
+}
+
+func secret(name string, data map[string][]byte) *coreV1.Secret {
+	return &coreV1.Secret{
+		ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: "webhooks"},
+		Type:       coreV1.SecretTypeTLS,
+		Data:       data,
+	}
+}
+
+func TestCAInjection(t *testing.T) {
+	ca, other := newTestCA(t, "webhook-ca"), newTestCA(t, "other-ca")
+	serving := ca.issue(t, "webhook.webhooks.svc")
+	later := time.Now().Add(time.Hour)
+
+	client := fake.NewSimpleClientset(
+		secret("webhook-tls", map[string][]byte{"ca.crt": ca.pem, "tls.crt": serving}),
+		secret("self-signed-tls", map[string][]byte{"tls.crt": ca.pem}),
+		secret("empty-tls", nil))
+	dynamicClient := dynamicFake.NewSimpleDynamicClient(runtime.NewScheme(),
+		certificate("webhook", "webhook-tls", true, later),
+		certificate("renewing", "webhook-tls", true, time.Now().Add(-time.Hour)),
+		certificate("issuing", "webhook-tls", false, later),
+		certificate("orphan", "gone-tls", true, later))
+	client.Resources = []*metaV1.APIResourceList{{
+		GroupVersion: "cert-manager.io/v1",
+		APIResources: []metaV1.APIResource{{Name: "certificates", Namespaced: true, Kind: "Certificate"}},
+	}}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		bundle      []byte
+		status      printer.CAInjectionStatus
+		ready       bool
+		renewing    bool
+		secret      string
+		// unread leaves the secrets unread, as without --read-secrets
+		unread bool
+	}{
+		{
+			name:        "certificate in sync",
+			annotations: map[string]string{injectCAFromAnnotation: "webhooks/webhook"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionInSync,
+			ready:       true,
+			secret:      "webhooks/webhook-tls",
+		},
+		{
+			name:        "certificate mismatch",
+			annotations: map[string]string{injectCAFromAnnotation: "webhooks/webhook"},
+			bundle:      other.pem,
+			status:      printer.CAInjectionMismatch,
+			ready:       true,
+			secret:      "webhooks/webhook-tls",
+		},
+		{
+			name:        "renewal time passed",
+			annotations: map[string]string{injectCAFromAnnotation: "webhooks/renewing"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionInSync,
+			ready:       true,
+			renewing:    true,
+			secret:      "webhooks/webhook-tls",
+		},
+		{
+			name:        "certificate not ready",
+			annotations: map[string]string{injectCAFromAnnotation: "webhooks/issuing"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionInSync,
+			secret:      "webhooks/webhook-tls",
+		},
+		{
+			name:        "certificate missing",
+			annotations: map[string]string{injectCAFromAnnotation: "webhooks/gone"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionCertificateMissing,
+		},
+		{
+			name:        "secret of certificate missing",
+			annotations: map[string]string{injectCAFromAnnotation: "webhooks/orphan"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionSecretMissing,
+			ready:       true,
+			secret:      "webhooks/gone-tls",
+		},
+		{
+			name:        "self-signed secret",
+			annotations: map[string]string{injectCAFromSecretAnnotation: "webhooks/self-signed-tls"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionInSync,
+			secret:      "webhooks/self-signed-tls",
+		},
+		{
+			name:        "secret without certificates",
+			annotations: map[string]string{injectCAFromSecretAnnotation: "webhooks/empty-tls"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionError,
+			secret:      "webhooks/empty-tls",
+		},
+		{
+			name:        "secret not read",
+			annotations: map[string]string{injectCAFromSecretAnnotation: "webhooks/self-signed-tls"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionUnchecked,
+			secret:      "webhooks/self-signed-tls",
+			unread:      true,
+		},
+		{
+			name:        "secret of certificate not read",
+			annotations: map[string]string{injectCAFromAnnotation: "webhooks/webhook"},
+			bundle:      other.pem,
+			status:      printer.CAInjectionUnchecked,
+			ready:       true,
+			secret:      "webhooks/webhook-tls",
+			unread:      true,
+		},
+		{
+			name:        "invalid reference",
+			annotations: map[string]string{injectCAFromAnnotation: "webhook"},
+			bundle:      ca.pem,
+			status:      printer.CAInjectionError,
+		},
+	}
+
+	w := NewWebHookClient(client)
+	w.SetDynamicClient(dynamicClient)
+	w.SetSecrets(true)
+	unread := NewWebHookClient(client)
+	unread.SetDynamicClient(dynamicClient)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := w
+			if tt.unread {
+				w = unread
+			}
+			source := w.caInjection(metaV1.ObjectMeta{Annotations: tt.annotations})
+			if source == nil {
+				t.Fatal("no CA injection found")
+			}
+			item := source.Check(tt.bundle)
+			if item.Status != tt.status {
+				t.Errorf("status = %s, want %s (error %q)", item.Status, tt.status, item.Error)
+			}
+			if item.Ready != tt.ready || item.Renewing != tt.renewing {
+				t.Errorf("ready, renewing = %v, %v, want %v, %v", item.Ready, item.Renewing, tt.ready, tt.renewing)
+			}
+			if item.Secret != tt.secret {
+				t.Errorf("secret = %q, want %q", item.Secret, tt.secret)
+			}
+		})
+	}
+
+	if source := w.caInjection(metaV1.ObjectMeta{}); source != nil {
+		t.Errorf("got %+v for a configuration without annotations, want nil", source.item)
+	}
+}
+
+func TestCAInjectionWithoutCertManager(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	w := NewWebHookClient(client)
+	w.SetDynamicClient(dynamicFake.NewSimpleDynamicClient(runtime.NewScheme()))
+	w.SetSecrets(true)
+
+	for i := 0; i < 2; i++ {
+		item := w.caInjection(metaV1.ObjectMeta{Annotations: map[string]string{injectCAFromAnnotation: "webhooks/webhook"}}).Check(nil)
+		if item.Status != printer.CAInjectionCertificateMissing || item.Error == "" {
+			t.Errorf("got %s (error %q), want certificate-missing with an error", item.Status, item.Error)
+		}
+	}
+	// discovery is asked once for every cert-manager version
+	if got := len(client.Actions()); got != len(certificateResources) {
+		t.Errorf("got %d discovery requests, want %d", got, len(certificateResources))
+	}
+}
+
+func TestSameCertificates(t *testing.T) {
+	a, b := newTestCA(t, "a"), newTestCA(t, "b")
+	ab := append(append([]byte{}, a.pem...), b.pem...)
+	ba := append(append([]byte("# bundle\n"), b.pem...), a.pem...)
+
+	for _, tt := range []struct {
+		name string
+		x, y []byte
+		want bool
+	}{
+		{name: "same", x: a.pem, y: a.pem, want: true},
+		{name: "reordered with text", x: ab, y: ba, want: true},
+		{name: "different", x: a.pem, y: b.pem},
+		{name: "subset", x: a.pem, y: ab},
+		{name: "empty", x: nil, y: nil},
+	} {
+		if got := sameCertificates(tt.x, tt.y); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sort"
+)
+
+// DiscoverResources returns every resource and subresource the cluster
+// serves, in every served version, and the group versions that failed
+// discovery, typically an unavailable aggregated API, whose resources
+// are unknown.
+func DiscoverResources(client discovery.DiscoveryInterface) ([]printer.APIResource, []schema.GroupVersion, error) {
+	_, lists, err := client.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, nil, err
+	}
+
+	var failed []schema.GroupVersion
+	if groupErr, ok := err.(*discovery.ErrGroupDiscoveryFailed); ok {
+		for gv := range groupErr.Groups {
+			failed = append(failed, gv)
+		}
+		sort.Slice(failed, func(i, j int) bool {
+			return failed[i].String() < failed[j].String()
+		})
+	}
+
+	var resources []printer.APIResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			resources = append(resources, printer.APIResource{
+				Group:      gv.Group,
+				Version:    gv.Version,
+				Resource:   r.Name,
+				Kind:       r.Kind,
+				Namespaced: r.Namespaced,
+				Verbs:      r.Verbs,
+			})
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Resource < b.Resource
+	})
+	return resources, failed, nil
+}
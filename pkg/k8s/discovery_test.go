@@ -0,0 +1,58 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverResources(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.Resources = []*metaV1.APIResourceList{
+		{GroupVersion: "apps/v1", APIResources: []metaV1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"create"}},
+		}},
+		{GroupVersion: "v1", APIResources: []metaV1.APIResource{
+			{Name: "pods", Kind: "Pod", Namespaced: true, Verbs: []string{"create", "delete"}},
+			{Name: "namespaces", Kind: "Namespace", Verbs: []string{"create"}},
+		}},
+	}
+
+	resources, failed, err := DiscoverResources(client.Discovery())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failed) != 0 {
+		t.Errorf("got failed group versions %v, want none", failed)
+	}
+	var got []string
+	for _, r := range resources {
+		got = append(got, fmt.Sprintf("%s/%s %s namespaced=%v %v", r.GroupVersion(), r.Resource, r.Kind, r.Namespaced, r.Verbs))
+	}
+	want := []string{
+		"v1/namespaces Namespace namespaced=false [create]",
+		"v1/pods Pod namespaced=true [create delete]",
+		"apps/v1/deployments Deployment namespaced=true [create]",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// configurationKinds are the kinds of the objects items are built from,
+// by item kind, as found in the involvedObject of their events.
+var configurationKinds = map[string]string{
+	"Mutating":   "MutatingWebhookConfiguration",
+	"Validating": "ValidatingWebhookConfiguration",
+}
+
+// recentFailures is the number of failure events kept per webhook.
+const recentFailures = 3
+
+// eventsPageSize is the number of events listed per request.
+const eventsPageSize = 500
+
+var (
+	// webhookNamePattern finds the webhook an API server admission error,
+	// such as `failed calling webhook "x"` or `admission webhook "x"
+	// denied the request`, is about.
+	webhookNamePattern = regexp.MustCompile(`webhook "([^"]+)"`)
+	// policyNamePattern finds the admission policy a denial is about.
+	policyNamePattern = regexp.MustCompile(`(Validating|Mutating)AdmissionPolicy '([^']+)'`)
+)
+
+// SetEvents enables attaching the recent events related to every webhook.
+func (w *WebHookClient) SetEvents(enabled bool) {
+	w.events = enabled
+}
+
+// SetFailures enables counting the events of the admission requests every
+// webhook failed or denied within the given duration.
+func (w *WebHookClient) SetFailures(since time.Duration) {
+	w.failuresSince = since
+}
+
+// fillEvents attaches the events about the configuration of every item,
+// its service, the service's pods and the requests it failed, oldest
+// first, and sums up its failures. Events are best effort, clusters
+// denying to list them are shown without.
+func (w *WebHookClient) fillEvents(items []printer.PrintItem) {
+	events, err := w.listEvents()
+	if err != nil {
+		return
+	}
+
+	since := time.Now().Add(-w.failuresSince)
+	for i := range items {
+		item := &items[i]
+		var failures printer.PrintFailuresItem
+
+		for _, event := range events {
+			e := eventItem(event)
+			failed, denied := failureEvent(*item, event.Message)
+			if w.events && (failed || denied || relatedEvent(*item, event.InvolvedObject)) {
+				item.Events = append(item.Events, e)
+			}
+
+			if w.failuresSince == 0 || (!failed && !denied) || e.LastSeen.Before(since) {
+				continue
+			}
+			// the count of an event that started before the window
+			// includes occurrences outside of it, of which only the last
+			// is known to be within
+			count := e.Count
+			if firstSeen(event).Before(since) {
+				count = 1
+			}
+			if failed {
+				failures.Failed += count
+			} else {
+				failures.Denied += count
+			}
+			failures.Recent = append(failures.Recent, e)
+		}
+
+		sort.SliceStable(item.Events, func(a, b int) bool {
+			return item.Events[a].LastSeen.Before(item.Events[b].LastSeen)
+		})
+		if len(failures.Recent) > 0 {
+			sort.SliceStable(failures.Recent, func(a, b int) bool {
+				return failures.Recent[a].LastSeen.After(failures.Recent[b].LastSeen)
+			})
+			failures.LastSeen = failures.Recent[0].LastSeen
+			if len(failures.Recent) > recentFailures {
+				failures.Recent = failures.Recent[:recentFailures]
+			}
+			item.Failures = &failures
+		}
+	}
+}
+
+// listEvents lists the events of all namespaces a page at a time, as
+// busy clusters keep many thousands of them.
+func (w *WebHookClient) listEvents() ([]coreV1.Event, error) {
+	var events []coreV1.Event
+	opts := metaV1.ListOptions{Limit: eventsPageSize}
+	for {
+		list, err := w.client.CoreV1().Events(metaV1.NamespaceAll).List(w.context, opts)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, list.Items...)
+		if list.Continue == "" {
+			return events, nil
+		}
+		opts.Continue = list.Continue
+	}
+}
+
+// failureEvent tells whether an event message is about a request the
+// item failed to be called for, or denied.
+func failureEvent(item printer.PrintItem, message string) (failed, denied bool) {
+	about := false
+	if item.Policy != nil {
+		for _, m := range policyNamePattern.FindAllStringSubmatch(message, -1) {
+			about = about || m[2] == item.Name
+		}
+	} else {
+		for _, m := range webhookNamePattern.FindAllStringSubmatch(message, -1) {
+			about = about || m[1] == item.Webhook.Name
+		}
+	}
+	if !about {
+		return false, false
+	}
+
+	if strings.Contains(message, "denied") && !strings.Contains(message, "failed calling webhook") {
+		return false, true
+	}
+	return true, false
+}
+
+// relatedEvent tells whether the given object of an event is the item's
+// configuration, service or one of the service's pods.
+func relatedEvent(item printer.PrintItem, object coreV1.ObjectReference) bool {
+	kind := configurationKinds[item.Kind]
+	if item.Policy != nil {
+		kind = "ValidatingAdmissionPolicy"
+		if item.Kind == "Mutating" {
+			kind = "MutatingAdmissionPolicy"
+		}
+	}
+	if object.Kind == kind && object.Name == item.Name {
+		return true
+	}
+
+	service := item.Webhook.Service
+	if !service.Found || object.Namespace != service.Namespace {
+		return false
+	}
+	switch object.Kind {
+	case "Service":
+		return object.Name == service.Name
+	case "Pod":
+		for _, ep := range service.Endpoints {
+			if ep.Pod == object.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstSeen returns when the given event first occurred.
+func firstSeen(event coreV1.Event) time.Time {
+	switch {
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+func eventItem(event coreV1.Event) printer.PrintEventItem {
+	lastSeen := event.LastTimestamp.Time
+	if lastSeen.IsZero() {
+		lastSeen = event.EventTime.Time
+	}
+	if lastSeen.IsZero() {
+		lastSeen = event.CreationTimestamp.Time
+	}
+
+	count := event.Count
+	if count == 0 {
+		count = 1
+	}
+
+	return printer.PrintEventItem{
+		Type:     event.Type,
+		Reason:   event.Reason,
+		Object:   event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+		Count:    count,
+		LastSeen: lastSeen,
+		Message:  event.Message,
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
+	"strings"
+	"testing"
+	"time"
+)
+
+func event(name, kind, objectName, reason string, count int32, lastSeen time.Time) *coreV1.Event {
+	return failure(name, kind, objectName, reason, "", count, lastSeen, lastSeen)
+}
+
+func failure(name, kind, objectName, reason, message string, count int32, firstSeen, lastSeen time.Time) *coreV1.Event {
+	return &coreV1.Event{
+		ObjectMeta:     metaV1.ObjectMeta{Name: name, Namespace: "webhooks"},
+		InvolvedObject: coreV1.ObjectReference{Kind: kind, Name: objectName, Namespace: "webhooks"},
+		Type:           coreV1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		Count:          count,
+		FirstTimestamp: metaV1.NewTime(firstSeen),
+		LastTimestamp:  metaV1.NewTime(lastSeen),
+	}
+}
+
+func TestFillEvents(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	client := fake.NewSimpleClientset(
+		event("configuration", "ValidatingWebhookConfiguration", "policy", "Updated", 0, now.Add(-time.Minute)),
+		event("service", "Service", "webhook", "FailedToUpdateEndpoint", 2, now.Add(-time.Hour)),
+		event("pod", "Pod", "webhook-0", "BackOff", 5, now.Add(-10*time.Minute)),
+		event("other-pod", "Pod", "webhook-1", "BackOff", 1, now),
+		event("policy", "ValidatingAdmissionPolicy", "replicas", "Invalid", 1, now),
+		event("same-name", "MutatingWebhookConfiguration", "policy", "Updated", 1, now))
+	w := NewWebHookClient(client)
+	w.SetEvents(true)
+
+	items := []printer.PrintItem{
+		{
+			Kind: "Validating",
+			Name: "policy",
+			Webhook: printer.PrintWebhookItem{Service: printer.PrintServiceItem{
+				Found:     true,
+				Name:      "webhook",
+				Namespace: "webhooks",
+				Endpoints: []printer.PrintEndpointItem{{IP: "10.0.0.1", Pod: "webhook-0"}},
+			}},
+		},
+		{Kind: "Validating", Name: "replicas", Policy: &printer.PrintPolicyItem{}},
+	}
+	w.fillEvents(items)
+
+	want := []string{
+		"Service/webhook FailedToUpdateEndpoint x2, Pod/webhook-0 BackOff x5, ValidatingWebhookConfiguration/policy Updated x1",
+		"ValidatingAdmissionPolicy/replicas Invalid x1",
+	}
+	for i, item := range items {
+		var got []string
+		for _, e := range item.Events {
+			got = append(got, fmt.Sprintf("%s %s x%d", e.Object, e.Reason, e.Count))
+		}
+		if strings.Join(got, ", ") != want[i] {
+			t.Errorf("%s: got %s, want %s", item.Name, strings.Join(got, ", "), want[i])
+		}
+	}
+}
+
+func TestFillEventsFailures(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		failure("failed", "ReplicaSet", "web", "FailedCreate", `Error creating: Internal error occurred: failed calling webhook "pods.policy.io": context deadline exceeded`, 4, now.Add(-10*time.Minute), now.Add(-time.Minute)),
+		failure("denied", "ReplicaSet", "api", "FailedCreate", `Error creating: admission webhook "pods.policy.io" denied the request: no latest tag`, 2, now.Add(-20*time.Minute), now.Add(-2*time.Minute)),
+		// only the last of the occurrences of an event started before the
+		// window is known to be within it
+		failure("ongoing", "ReplicaSet", "batch", "FailedCreate", `failed calling webhook "pods.policy.io": EOF`, 7, now.Add(-3*time.Hour), now.Add(-5*time.Minute)),
+		failure("old", "ReplicaSet", "old", "FailedCreate", `failed calling webhook "pods.policy.io": connection refused`, 9, now.Add(-3*time.Hour), now.Add(-2*time.Hour)),
+		failure("other", "ReplicaSet", "web", "FailedCreate", `failed calling webhook "pods.other.io": EOF`, 1, now, now),
+		failure("policy", "Deployment", "web", "FailedCreate", `ValidatingAdmissionPolicy 'replicas' with binding 'replicas' denied request: too many replicas`, 1, now, now))
+	w := NewWebHookClient(client)
+	w.SetFailures(time.Hour)
+
+	tests := []struct {
+		name   string
+		item   printer.PrintItem
+		want   string
+		recent string
+	}{
+		{
+			name:   "webhook",
+			item:   printer.PrintItem{Kind: "Validating", Name: "policy", Webhook: printer.PrintWebhookItem{Name: "pods.policy.io"}},
+			want:   "5 failed, 2 denied",
+			recent: "ReplicaSet/web, ReplicaSet/api, ReplicaSet/batch",
+		},
+		{
+			name:   "policy",
+			item:   printer.PrintItem{Kind: "Validating", Name: "replicas", Policy: &printer.PrintPolicyItem{}},
+			want:   "0 failed, 1 denied",
+			recent: "Deployment/web",
+		},
+		{
+			name: "no failures",
+			item: printer.PrintItem{Kind: "Validating", Name: "quiet", Webhook: printer.PrintWebhookItem{Name: "pods.quiet.io"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := []printer.PrintItem{tt.item}
+			w.fillEvents(items)
+			failures := items[0].Failures
+
+			if tt.want == "" {
+				if failures != nil {
+					t.Errorf("got failures %+v, want none", failures)
+				}
+				return
+			}
+			if failures == nil {
+				t.Fatalf("got no failures, want %s", tt.want)
+			}
+			if got := fmt.Sprintf("%d failed, %d denied", failures.Failed, failures.Denied); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			var recent []string
+			for _, e := range failures.Recent {
+				recent = append(recent, e.Object)
+			}
+			if strings.Join(recent, ", ") != tt.recent {
+				t.Errorf("recent = %s, want %s", strings.Join(recent, ", "), tt.recent)
+			}
+			if len(items[0].Events) > 0 {
+				t.Errorf("got events %+v without asking for them", items[0].Events)
+			}
+		})
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	// the fake client does not record the continue token, so the pages
+	// are served in order
+	pages := []*coreV1.EventList{
+		{ListMeta: metaV1.ListMeta{Continue: "page-2"}, Items: []coreV1.Event{{ObjectMeta: metaV1.ObjectMeta{Name: "first"}}}},
+		{Items: []coreV1.Event{{ObjectMeta: metaV1.ObjectMeta{Name: "second"}}}},
+	}
+	client.PrependReactor("list", "events", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		page := pages[0]
+		pages = pages[1:]
+		return true, page, nil
+	})
+
+	events, err := NewWebHookClient(client).listEvents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range events {
+		names = append(names, e.Name)
+	}
+	if got := strings.Join(names, ","); got != "first,second" {
+		t.Errorf("got events %s, want first,second", got)
+	}
+}
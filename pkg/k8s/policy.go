@@ -0,0 +1,308 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	admissionV1 "k8s.io/api/admissionregistration/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sort"
+)
+
+// policyVersions are the admissionregistration.k8s.io versions admission
+// policies are looked up in, newest first. They are read through the
+// dynamic client, as the typed clients of this client-go release only
+// cover some of these versions and no MutatingAdmissionPolicy.
+var policyVersions = []string{"v1", "v1beta1", "v1alpha1"}
+
+const (
+	validatingPolicyResource        = "validatingadmissionpolicies"
+	validatingPolicyBindingResource = "validatingadmissionpolicybindings"
+	mutatingPolicyResource          = "mutatingadmissionpolicies"
+	mutatingPolicyBindingResource   = "mutatingadmissionpolicybindings"
+)
+
+// admissionPolicy is the subset of a Validating or MutatingAdmissionPolicy
+// this plugin shows.
+type admissionPolicy struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		ParamKind *struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+		} `json:"paramKind"`
+		MatchConstraints *matchResources `json:"matchConstraints"`
+		Validations      []struct {
+			Expression        string `json:"expression"`
+			Message           string `json:"message"`
+			MessageExpression string `json:"messageExpression"`
+			Reason            string `json:"reason"`
+		} `json:"validations"`
+		Mutations []struct {
+			PatchType          string `json:"patchType"`
+			ApplyConfiguration *struct {
+				Expression string `json:"expression"`
+			} `json:"applyConfiguration"`
+			JSONPatch *struct {
+				Expression string `json:"expression"`
+			} `json:"jsonPatch"`
+		} `json:"mutations"`
+		ReinvocationPolicy string                         `json:"reinvocationPolicy"`
+		FailurePolicy      *admissionV1.FailurePolicyType `json:"failurePolicy"`
+		MatchConditions    []admissionV1.MatchCondition   `json:"matchConditions"`
+	} `json:"spec"`
+}
+
+// admissionPolicyBinding is the subset of a Validating or
+// MutatingAdmissionPolicyBinding this plugin shows.
+type admissionPolicyBinding struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata"`
+	Spec              struct {
+		PolicyName string `json:"policyName"`
+		ParamRef   *struct {
+			Name      string                `json:"name"`
+			Namespace string                `json:"namespace"`
+			Selector  *metaV1.LabelSelector `json:"selector"`
+		} `json:"paramRef"`
+		MatchResources    *matchResources `json:"matchResources"`
+		ValidationActions []string        `json:"validationActions"`
+	} `json:"spec"`
+}
+
+type matchResources struct {
+	NamespaceSelector    *metaV1.LabelSelector        `json:"namespaceSelector"`
+	ObjectSelector       *metaV1.LabelSelector        `json:"objectSelector"`
+	ResourceRules        []namedRuleWithOperations    `json:"resourceRules"`
+	ExcludeResourceRules []namedRuleWithOperations    `json:"excludeResourceRules"`
+	MatchPolicy          *admissionV1.MatchPolicyType `json:"matchPolicy"`
+}
+
+type namedRuleWithOperations struct {
+	ResourceNames []string               `json:"resourceNames"`
+	Operations    []string               `json:"operations"`
+	APIGroups     []string               `json:"apiGroups"`
+	APIVersions   []string               `json:"apiVersions"`
+	Resources     []string               `json:"resources"`
+	Scope         *admissionV1.ScopeType `json:"scope"`
+}
+
+// fetchPolicies lists the admission policy resource of the given name
+// from the newest version the cluster serves. Clusters serving none of
+// them, or a WebHookClient without dynamic client, have no policies.
+// Policies are best effort, users that may not list them, or clusters
+// failing to, get a warning and no policies instead of an error.
+func (w *WebHookClient) fetchPolicies(resource string, warnings *[]string) []unstructured.Unstructured {
+	if w.dynamic == nil {
+		return nil
+	}
+
+	for _, version := range policyVersions {
+		gvr := schema.GroupVersionResource{Group: admissionV1.GroupName, Version: version, Resource: resource}
+		list, err := w.dynamic.Resource(gvr).List(w.context, metaV1.ListOptions{})
+		if apiErrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			*warnings = append(*warnings, fmt.Sprintf("%s are not shown: %v", resource, err))
+			return nil
+		}
+		return list.Items
+	}
+	return nil
+}
+
+// fillPolicies adds an item of the given kind for the given admission
+// policy with the bindings that apply it.
+func (w *WebHookClient) fillPolicies(kind string, raw unstructured.Unstructured, rawBindings []unstructured.Unstructured, items *[]printer.PrintItem) {
+	var policy admissionPolicy
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw.Object, &policy); err != nil {
+		return
+	}
+
+	item := printer.PrintItem{
+		Kind:       kind,
+		Name:       policy.Name,
+		Webhook:    printer.PrintWebhookItem{Name: policy.Name},
+		Provenance: provenance(policy.ObjectMeta),
+		Policy:     &printer.PrintPolicyItem{},
+	}
+
+	// unlike webhooks, policies fail closed by default
+	item.FailurePolicy = string(admissionV1.Fail)
+	if policy.Spec.FailurePolicy != nil {
+		item.FailurePolicy = string(*policy.Spec.FailurePolicy)
+	}
+
+	if pk := policy.Spec.ParamKind; pk != nil {
+		item.Policy.ParamKind = pk.APIVersion + "/" + pk.Kind
+	}
+	for _, v := range policy.Spec.Validations {
+		message := v.Message
+		if message == "" {
+			message = v.MessageExpression
+		}
+		item.Policy.Validations = append(item.Policy.Validations, printer.PrintValidationItem{
+			Expression: v.Expression,
+			Message:    message,
+			Reason:     v.Reason,
+		})
+	}
+
+	for _, m := range policy.Spec.Mutations {
+		mutation := printer.PrintMutationItem{PatchType: m.PatchType}
+		switch {
+		case m.ApplyConfiguration != nil:
+			mutation.Expression = m.ApplyConfiguration.Expression
+		case m.JSONPatch != nil:
+			mutation.Expression = m.JSONPatch.Expression
+		}
+		item.Policy.Mutations = append(item.Policy.Mutations, mutation)
+	}
+	item.Policy.ReinvocationPolicy = policy.Spec.ReinvocationPolicy
+	item.MatchConditions = matchConditionItems(policy.Spec.MatchConditions)
+
+	var constraintRules, constraintExclusions []printer.ResourceModel
+	var constraintNamespaces []string
+	if mc := policy.Spec.MatchConstraints; mc != nil {
+		constraintRules, constraintExclusions = policyRules(mc.ResourceRules), policyRules(mc.ExcludeResourceRules)
+		item.NamespaceSelector = mc.NamespaceSelector
+		item.ObjectSelector = mc.ObjectSelector
+		item.MatchPolicy = string(admissionV1.Equivalent)
+		if mc.MatchPolicy != nil {
+			item.MatchPolicy = string(*mc.MatchPolicy)
+		}
+		constraintNamespaces = w.matchNamespaces(mc.NamespaceSelector)
+	}
+
+	active := map[string]bool{}
+	var bound, boundExclusions []printer.ResourceModel
+	for _, rb := range rawBindings {
+		var binding admissionPolicyBinding
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rb.Object, &binding); err != nil {
+			continue
+		}
+		if binding.Spec.PolicyName != policy.Name {
+			continue
+		}
+
+		bindingItem := printer.PrintPolicyBindingItem{
+			Name:              binding.Name,
+			ValidationActions: binding.Spec.ValidationActions,
+		}
+		if ref := binding.Spec.ParamRef; ref != nil {
+			switch {
+			case ref.Name != "":
+				bindingItem.ParamRef = ref.Name
+				if ref.Namespace != "" {
+					bindingItem.ParamRef = ref.Namespace + "/" + ref.Name
+				}
+			case ref.Selector != nil:
+				bindingItem.ParamRef = metaV1.FormatLabelSelector(ref.Selector)
+			}
+		}
+
+		// a binding narrows the policy's constraints, it cannot widen them
+		namespaces := constraintNamespaces
+		rules, exclusions := constraintRules, constraintExclusions
+		if mr := binding.Spec.MatchResources; mr != nil {
+			if mr.NamespaceSelector != nil {
+				namespaces = intersect(namespaces, w.matchNamespaces(mr.NamespaceSelector))
+			}
+			if len(mr.ResourceRules) > 0 {
+				rules = narrowRules(rules, policyRules(mr.ResourceRules))
+			}
+			exclusions = match.Union(append([]printer.ResourceModel(nil), exclusions...), policyRules(mr.ExcludeResourceRules))
+			bindingItem.NamespaceSelector = mr.NamespaceSelector
+			bindingItem.ObjectSelector = mr.ObjectSelector
+		}
+		rules, exclusions = match.Exclude(rules, exclusions)
+		bindingItem.ActiveNamespaces = namespaces
+		bindingItem.ResourceModels = rules
+		bindingItem.ExcludedResourceModels = exclusions
+		for _, ns := range namespaces {
+			active[ns] = true
+		}
+		bound = match.Union(bound, rules)
+		boundExclusions = match.Union(boundExclusions, exclusions)
+
+		item.Policy.Bindings = append(item.Policy.Bindings, bindingItem)
+	}
+
+	// the rules of a bound policy are those of its bindings together
+	item.ResourceModels, item.ExcludedResourceModels = match.Exclude(constraintRules, constraintExclusions)
+	if len(item.Policy.Bindings) > 0 {
+		item.ResourceModels, item.ExcludedResourceModels = bound, boundExclusions
+	}
+
+	for ns := range active {
+		item.ActiveNamespaces = append(item.ActiveNamespaces, ns)
+	}
+	sort.Strings(item.ActiveNamespaces)
+
+	*items = append(*items, item)
+}
+
+// policyRules converts the resource rules of a policy's match
+// constraints into ResourceModels.
+func policyRules(rules []namedRuleWithOperations) []printer.ResourceModel {
+	var resources []printer.ResourceModel
+	for _, rule := range rules {
+		resources = append(resources, printer.ResourceModel{
+			APIGroups:     rule.APIGroups,
+			APIVersions:   rule.APIVersions,
+			Operations:    rule.Operations,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+			Scope:         ruleScope(rule.Scope),
+		})
+	}
+	return resources
+}
+
+// narrowRules intersects every rule with every narrowing rule.
+func narrowRules(rules, narrowing []printer.ResourceModel) []printer.ResourceModel {
+	var result []printer.ResourceModel
+	for _, rm := range rules {
+		for _, n := range narrowing {
+			if r, ok := match.Intersect(rm, n); ok {
+				result = match.Union(result, []printer.ResourceModel{r})
+			}
+		}
+	}
+	return result
+}
+
+func intersect(a, b []string) []string {
+	in := map[string]bool{}
+	for _, s := range b {
+		in[s] = true
+	}
+	var result []string
+	for _, s := range a {
+		if in[s] {
+			result = append(result, s)
+		}
+	}
+	return result
+}
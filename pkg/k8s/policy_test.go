@@ -0,0 +1,287 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+	"strings"
+	"testing"
+)
+
+// policy returns a ValidatingAdmissionPolicy on deployments constrained
+// to the namespaces labelled env=prod.
+func policy(name string, spec map[string]interface{}) unstructured.Unstructured {
+	base := map[string]interface{}{
+		"matchConstraints": map[string]interface{}{
+			"namespaceSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"env": "prod"}},
+			"resourceRules": []interface{}{map[string]interface{}{
+				"apiGroups":   []interface{}{"apps"},
+				"apiVersions": []interface{}{"v1"},
+				"operations":  []interface{}{"CREATE", "UPDATE"},
+				"resources":   []interface{}{"deployments"},
+			}},
+		},
+		"validations": []interface{}{map[string]interface{}{
+			"expression": "object.spec.replicas <= 5",
+			"message":    "too many replicas",
+		}},
+	}
+	for k, v := range spec {
+		base[k] = v
+	}
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "admissionregistration.k8s.io/v1",
+		"kind":       "ValidatingAdmissionPolicy",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       base,
+	}}
+}
+
+func resourceRule(operations []interface{}, resources ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"apiGroups":   []interface{}{"apps"},
+		"apiVersions": []interface{}{"v1"},
+		"operations":  operations,
+		"resources":   resources,
+	}
+}
+
+// rules formats the given rules as operations and resources.
+func rules(resourceModels []printer.ResourceModel) string {
+	var result []string
+	for _, rm := range resourceModels {
+		result = append(result, strings.Join(rm.Operations, ",")+" "+strings.Join(rm.Resources, ","))
+	}
+	return strings.Join(result, "; ")
+}
+
+func binding(name, policyName string, spec map[string]interface{}) unstructured.Unstructured {
+	base := map[string]interface{}{
+		"policyName":        policyName,
+		"validationActions": []interface{}{"Deny"},
+	}
+	for k, v := range spec {
+		base[k] = v
+	}
+	return unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "admissionregistration.k8s.io/v1",
+		"kind":       "ValidatingAdmissionPolicyBinding",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       base,
+	}}
+}
+
+func TestFillPolicies(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		namespace("payments", map[string]string{"env": "prod", "team": "payments"}),
+		namespace("checkout", map[string]string{"env": "prod", "team": "checkout"}),
+		namespace("sandbox", map[string]string{"env": "dev", "team": "payments"}))
+	w := NewWebHookClient(client)
+
+	teamPayments := map[string]interface{}{
+		"matchResources": map[string]interface{}{
+			"namespaceSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"team": "payments"}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		policy        unstructured.Unstructured
+		bindings      []unstructured.Unstructured
+		failurePolicy string
+		active        string
+		bindingActive []string
+		rules         string
+		excluded      string
+	}{
+		{
+			name:          "unbound",
+			policy:        policy("replicas", nil),
+			bindings:      []unstructured.Unstructured{binding("other", "other-policy", nil)},
+			failurePolicy: "Fail",
+			rules:         "CREATE,UPDATE deployments",
+		},
+		{
+			name:          "bound without matchResources",
+			policy:        policy("replicas", nil),
+			bindings:      []unstructured.Unstructured{binding("replicas", "replicas", nil)},
+			failurePolicy: "Fail",
+			active:        "checkout,payments",
+			bindingActive: []string{"checkout,payments"},
+			rules:         "CREATE,UPDATE deployments",
+		},
+		{
+			name:          "binding narrows the constraints",
+			policy:        policy("replicas", map[string]interface{}{"failurePolicy": "Ignore"}),
+			bindings:      []unstructured.Unstructured{binding("replicas-payments", "replicas", teamPayments)},
+			failurePolicy: "Ignore",
+			active:        "payments",
+			bindingActive: []string{"payments"},
+			rules:         "CREATE,UPDATE deployments",
+		},
+		{
+			name:   "union of the bindings",
+			policy: policy("replicas", nil),
+			bindings: []unstructured.Unstructured{
+				binding("replicas-payments", "replicas", teamPayments),
+				binding("replicas", "replicas", nil),
+			},
+			failurePolicy: "Fail",
+			active:        "checkout,payments",
+			bindingActive: []string{"payments", "checkout,payments"},
+			rules:         "CREATE,UPDATE deployments",
+		},
+		{
+			name:   "binding narrows the rules",
+			policy: policy("replicas", nil),
+			bindings: []unstructured.Unstructured{binding("replicas-create", "replicas", map[string]interface{}{
+				"matchResources": map[string]interface{}{
+					"resourceRules": []interface{}{resourceRule([]interface{}{"CREATE", "DELETE"}, "*")},
+				},
+			})},
+			failurePolicy: "Fail",
+			active:        "checkout,payments",
+			bindingActive: []string{"checkout,payments"},
+			rules:         "CREATE deployments",
+		},
+		{
+			name:   "binding with rules of other resources",
+			policy: policy("replicas", nil),
+			bindings: []unstructured.Unstructured{binding("replicas-daemonsets", "replicas", map[string]interface{}{
+				"matchResources": map[string]interface{}{
+					"resourceRules": []interface{}{resourceRule([]interface{}{"*"}, "daemonsets")},
+				},
+			})},
+			failurePolicy: "Fail",
+			active:        "checkout,payments",
+			bindingActive: []string{"checkout,payments"},
+		},
+		{
+			name: "excluded operation",
+			policy: policy("replicas", map[string]interface{}{"matchConstraints": map[string]interface{}{
+				"resourceRules":        []interface{}{resourceRule([]interface{}{"CREATE", "UPDATE"}, "deployments", "statefulsets")},
+				"excludeResourceRules": []interface{}{resourceRule([]interface{}{"UPDATE"}, "deployments", "statefulsets")},
+			}}),
+			failurePolicy: "Fail",
+			rules:         "CREATE deployments,statefulsets",
+		},
+		{
+			name:   "binding excludes a resource",
+			policy: policy("replicas", nil),
+			bindings: []unstructured.Unstructured{binding("replicas", "replicas", map[string]interface{}{
+				"matchResources": map[string]interface{}{
+					"excludeResourceRules": []interface{}{resourceRule([]interface{}{"*"}, "deployments")},
+				},
+			})},
+			failurePolicy: "Fail",
+			active:        "checkout,payments",
+			bindingActive: []string{"checkout,payments"},
+		},
+		{
+			name: "exclusion by name",
+			policy: policy("replicas", map[string]interface{}{"matchConstraints": map[string]interface{}{
+				"resourceRules": []interface{}{resourceRule([]interface{}{"CREATE"}, "deployments")},
+				"excludeResourceRules": []interface{}{map[string]interface{}{
+					"apiGroups":     []interface{}{"apps"},
+					"apiVersions":   []interface{}{"v1"},
+					"operations":    []interface{}{"*"},
+					"resources":     []interface{}{"deployments"},
+					"resourceNames": []interface{}{"coredns"},
+				}},
+			}}),
+			failurePolicy: "Fail",
+			rules:         "CREATE deployments",
+			excluded:      "* deployments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []printer.PrintItem
+			w.fillPolicies("Policy", tt.policy, tt.bindings, &items)
+			if len(items) != 1 {
+				t.Fatalf("got %d items, want 1", len(items))
+			}
+			item := items[0]
+
+			if item.Kind != "Policy" || item.FailurePolicy != tt.failurePolicy {
+				t.Errorf("got %s failing %s, want Policy failing %s", item.Kind, item.FailurePolicy, tt.failurePolicy)
+			}
+			if got := strings.Join(item.ActiveNamespaces, ","); got != tt.active {
+				t.Errorf("active namespaces = %s, want %s", got, tt.active)
+			}
+			var bindingActive []string
+			for _, b := range item.Policy.Bindings {
+				bindingActive = append(bindingActive, strings.Join(b.ActiveNamespaces, ","))
+			}
+			if strings.Join(bindingActive, " ") != strings.Join(tt.bindingActive, " ") {
+				t.Errorf("binding namespaces = %v, want %v", bindingActive, tt.bindingActive)
+			}
+			if got := rules(item.ResourceModels); got != tt.rules {
+				t.Errorf("rules = %q, want %q", got, tt.rules)
+			}
+			if got := rules(item.ExcludedResourceModels); got != tt.excluded {
+				t.Errorf("excluded rules = %q, want %q", got, tt.excluded)
+			}
+			if len(item.Policy.Validations) != 1 || item.Policy.Validations[0].Message != "too many replicas" {
+				t.Errorf("validations = %+v, want the replicas validation", item.Policy.Validations)
+			}
+		})
+	}
+}
+
+func TestFillPoliciesMutations(t *testing.T) {
+	w := NewWebHookClient(fake.NewSimpleClientset(namespace("payments", map[string]string{"env": "prod"})))
+	raw := policy("sidecar", map[string]interface{}{
+		"reinvocationPolicy": "IfNeeded",
+		"mutations": []interface{}{
+			map[string]interface{}{
+				"patchType":          "ApplyConfiguration",
+				"applyConfiguration": map[string]interface{}{"expression": "Object{metadata: Object.metadata{labels: {\"mesh\": \"on\"}}}"},
+			},
+			map[string]interface{}{
+				"patchType": "JSONPatch",
+				"jsonPatch": map[string]interface{}{"expression": "[JSONPatch{op: \"remove\", path: \"/spec/hostNetwork\"}]"},
+			},
+		},
+	})
+	raw.SetKind("MutatingAdmissionPolicy")
+
+	var items []printer.PrintItem
+	w.fillPolicies("Mutating", raw, []unstructured.Unstructured{binding("sidecar", "sidecar", nil)}, &items)
+	if len(items) != 1 {
+		t.Fatalf("got %d items, want 1", len(items))
+	}
+	item := items[0]
+
+	if item.Kind != "Mutating" || item.Policy.ReinvocationPolicy != "IfNeeded" {
+		t.Errorf("got %s reinvoked %s, want Mutating reinvoked IfNeeded", item.Kind, item.Policy.ReinvocationPolicy)
+	}
+	var mutations []string
+	for _, m := range item.Policy.Mutations {
+		mutations = append(mutations, m.PatchType+": "+m.Expression)
+	}
+	want := []string{
+		`ApplyConfiguration: Object{metadata: Object.metadata{labels: {"mesh": "on"}}}`,
+		`JSONPatch: [JSONPatch{op: "remove", path: "/spec/hostNetwork"}]`,
+	}
+	if strings.Join(mutations, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got mutations\n%s\nwant\n%s", strings.Join(mutations, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,266 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"io/ioutil"
+	admissionV1 "k8s.io/api/admissionregistration/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Prober performs a TLS handshake against each webhook endpoint and
+// checks the served certificate against the webhook's CABundle.
+//
+// Service backed webhooks are reached through a port-forward to one of the
+// service's ready pods. The API server's services proxy is not usable here
+// because it terminates TLS itself and never exposes the served certificate.
+type Prober struct {
+	client  kubernetes.Interface
+	config  *rest.Config
+	context context.Context
+	timeout time.Duration
+}
+
+// NewProber constructs a new Prober with the specified output
+// of kubernetes.Interface and the *rest.Config used for port-forwarding
+func NewProber(client kubernetes.Interface, config *rest.Config, timeout time.Duration) *Prober {
+	return &Prober{
+		client:  client,
+		config:  config,
+		context: context.Background(),
+		timeout: timeout,
+	}
+}
+
+// Probe dials the endpoint described by the given client config and
+// returns what it found.
+func (p *Prober) Probe(cc admissionV1.WebhookClientConfig) *printer.PrintProbeItem {
+	result := &printer.PrintProbeItem{}
+
+	roots, bundleNotAfter, err := parseCABundle(cc.CABundle)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.CABundleNotAfter = bundleNotAfter
+
+	var address string
+	switch {
+	case cc.URL != nil:
+		u, err := url.Parse(*cc.URL)
+		if err != nil {
+			result.Error = fmt.Sprintf("invalid url: %v", err)
+			return result
+		}
+		port := u.Port()
+		if port == "" {
+			port = "443"
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+		result.Via = "url"
+		result.Target = address
+		result.ServerName = u.Hostname()
+	case cc.Service != nil:
+		port := int32(443)
+		if cc.Service.Port != nil {
+			port = *cc.Service.Port
+		}
+		local, stop, err := p.portForward(cc.Service.Namespace, cc.Service.Name, port)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		defer close(stop)
+		address = local
+		result.Via = "port-forward"
+		result.Target = fmt.Sprintf("%s/%s:%d", cc.Service.Namespace, cc.Service.Name, port)
+		result.ServerName = fmt.Sprintf("%s.%s.svc", cc.Service.Name, cc.Service.Namespace)
+	default:
+		result.Error = "webhook has neither url nor service"
+		return result
+	}
+
+	dialer := &net.Dialer{Timeout: p.timeout}
+	start := time.Now()
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName: result.ServerName,
+		// verification is done below against the CABundle so that an
+		// untrusted certificate can still be reported on
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("handshake failed: %v", err)
+		return result
+	}
+	result.Latency = time.Since(start)
+	defer conn.Close()
+
+	peers := conn.ConnectionState().PeerCertificates
+	if len(peers) == 0 {
+		result.Error = "no certificate presented"
+		return result
+	}
+	result.ServedNotAfter = peers[0].NotAfter
+
+	intermediates := x509.NewCertPool()
+	for _, c := range peers[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err = peers[0].Verify(x509.VerifyOptions{
+		DNSName:       result.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		result.VerifyError = err.Error()
+	} else {
+		result.Verified = true
+	}
+
+	return result
+}
+
+// portForward opens a port-forward to a ready pod backing the given
+// service port and returns the local address with a channel to stop it.
+func (p *Prober) portForward(ns, name string, port int32) (string, chan struct{}, error) {
+	svc, err := p.client.CoreV1().Services(ns).Get(p.context, name, metaV1.GetOptions{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var servicePort *coreV1.ServicePort
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Port == port {
+			servicePort = &svc.Spec.Ports[i]
+		}
+	}
+	if servicePort == nil {
+		return "", nil, fmt.Errorf("service %s/%s has no port %d", ns, name, port)
+	}
+
+	endpoints, err := p.client.CoreV1().Endpoints(ns).Get(p.context, name, metaV1.GetOptions{})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var pod string
+	var podPort int32
+subsets:
+	for _, subset := range endpoints.Subsets {
+		for _, ep := range subset.Ports {
+			if ep.Name != servicePort.Name {
+				continue
+			}
+			for _, addr := range subset.Addresses {
+				if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
+					pod, podPort = addr.TargetRef.Name, ep.Port
+					break subsets
+				}
+			}
+		}
+	}
+	if pod == "" {
+		return "", nil, fmt.Errorf("service %s/%s has no ready pods", ns, name)
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(p.config)
+	if err != nil {
+		return "", nil, err
+	}
+	req := p.client.CoreV1().RESTClient().Post().Resource("pods").Namespace(ns).Name(pod).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stop := make(chan struct{})
+	ready := make(chan struct{})
+	fw, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{"0:" + strconv.Itoa(int(podPort))}, stop, ready, ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return "", nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fw.ForwardPorts()
+	}()
+
+	select {
+	case <-ready:
+	case err := <-errCh:
+		return "", nil, fmt.Errorf("port-forward to pod %s/%s: %v", ns, pod, err)
+	case <-time.After(p.timeout):
+		close(stop)
+		return "", nil, fmt.Errorf("port-forward to pod %s/%s timed out", ns, pod)
+	}
+
+	ports, err := fw.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stop)
+		return "", nil, errors.New("port-forward did not report a local port")
+	}
+
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(int(ports[0].Local))), stop, nil
+}
+
+// parseCABundle builds a certificate pool from the given CABundle and
+// returns the earliest expiry among its certificates. An empty bundle
+// falls back to the system roots, as the API server does.
+func parseCABundle(bundle []byte) (*x509.CertPool, time.Time, error) {
+	var notAfter time.Time
+
+	if len(bundle) == 0 {
+		roots, err := x509.SystemCertPool()
+		return roots, notAfter, err
+	}
+
+	roots := x509.NewCertPool()
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, notAfter, fmt.Errorf("invalid CABundle: %v", err)
+		}
+		roots.AddCert(cert)
+		if notAfter.IsZero() || cert.NotAfter.Before(notAfter) {
+			notAfter = cert.NotAfter
+		}
+	}
+
+	if notAfter.IsZero() {
+		return nil, notAfter, errors.New("CABundle contains no certificates")
+	}
+	return roots, notAfter, nil
+}
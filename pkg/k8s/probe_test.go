@@ -0,0 +1,152 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/fakewebhook"
+	admissionV1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeWebhook serves a fake webhook until the test ends.
+func startFakeWebhook(t *testing.T) *fakewebhook.Server {
+	t.Helper()
+	server, err := fakewebhook.NewServer(*fakewebhook.NewOptions())
+	if err != nil {
+		t.Fatalf("starting fake webhook: %v", err)
+	}
+	go server.Serve()
+	t.Cleanup(func() {
+		_ = server.Shutdown(context.Background())
+	})
+	return server
+}
+
+func TestProbe(t *testing.T) {
+	server := startFakeWebhook(t)
+	other := startFakeWebhook(t)
+	url := server.URL()
+	closed := "https://127.0.0.1:1/admit"
+
+	tests := []struct {
+		name         string
+		clientConfig admissionV1.WebhookClientConfig
+		verified     bool
+		verifyError  string
+		error        string
+	}{
+		{
+			name:         "trusted by the CABundle",
+			clientConfig: admissionV1.WebhookClientConfig{URL: &url, CABundle: server.CABundle()},
+			verified:     true,
+		},
+		{
+			name:         "CABundle of another CA",
+			clientConfig: admissionV1.WebhookClientConfig{URL: &url, CABundle: other.CABundle()},
+			verifyError:  "unknown authority",
+		},
+		{
+			name:         "nothing listening",
+			clientConfig: admissionV1.WebhookClientConfig{URL: &closed, CABundle: server.CABundle()},
+			error:        "connect",
+		},
+		{
+			name:         "CABundle without certificates",
+			clientConfig: admissionV1.WebhookClientConfig{URL: &url, CABundle: []byte("not a certificate")},
+			error:        "contains no certificates",
+		},
+		{
+			name:         "neither url nor service",
+			clientConfig: admissionV1.WebhookClientConfig{CABundle: server.CABundle()},
+			error:        "neither url nor service",
+		},
+	}
+
+	// the client is only used to port-forward to services
+	prober := NewProber(nil, nil, 5*time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := prober.Probe(tt.clientConfig)
+			if probe.Verified != tt.verified {
+				t.Errorf("verified = %v, want %v (verifyError %q, error %q)", probe.Verified, tt.verified, probe.VerifyError, probe.Error)
+			}
+			if !strings.Contains(probe.VerifyError, tt.verifyError) || (tt.verifyError == "") != (probe.VerifyError == "") {
+				t.Errorf("verifyError = %q, want it to contain %q", probe.VerifyError, tt.verifyError)
+			}
+			if !strings.Contains(probe.Error, tt.error) || (tt.error == "") != (probe.Error == "") {
+				t.Errorf("error = %q, want it to contain %q", probe.Error, tt.error)
+			}
+		})
+	}
+}
+
+func TestBuildProbesConfiguration(t *testing.T) {
+	server := startFakeWebhook(t)
+	options := fakewebhook.NewConfigurationOptions()
+	configuration, err := server.Configuration(*options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(configuration,
+		namespace("default", nil),
+		namespace("kube-system", nil))
+	w := NewWebHookClient(client)
+	w.SetProber(NewProber(client, nil, 5*time.Second))
+
+	configurations, err := w.Fetch(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := w.Build(configurations)
+	if len(model.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(model.Items))
+	}
+
+	item := model.Items[0]
+	if item.Kind != "Validating" || item.Name != options.Name {
+		t.Errorf("got %s %s, want Validating %s", item.Kind, item.Name, options.Name)
+	}
+	if item.Webhook.URL == nil || *item.Webhook.URL != server.URL() {
+		t.Errorf("url = %v, want %s", item.Webhook.URL, server.URL())
+	}
+	if got := strings.Join(item.ActiveNamespaces, ","); got != "default,kube-system" {
+		t.Errorf("active namespaces = %s, want default,kube-system", got)
+	}
+	if item.ValidUntil <= 0 {
+		t.Errorf("valid until = %v, want the lifetime of the generated CA", item.ValidUntil)
+	}
+	if probe := item.Webhook.Probe; probe == nil || !probe.Verified {
+		t.Errorf("probe = %+v, want a verified probe", probe)
+	}
+}
+
+func TestRetrieveValidDateCount(t *testing.T) {
+	for name, bundle := range map[string][]byte{
+		"nil":          nil,
+		"not PEM":      []byte("not a certificate"),
+		"invalid cert": []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n"),
+	} {
+		if got := retrieveValidDateCount(bundle); got != 0 {
+			t.Errorf("%s: got %v, want 0", name, got)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+)
+
+const (
+	helmReleaseNameAnnotation      = "meta.helm.sh/release-name"
+	helmReleaseNamespaceAnnotation = "meta.helm.sh/release-namespace"
+	managedByLabel                 = "app.kubernetes.io/managed-by"
+	injectCAFromAnnotation         = "cert-manager.io/inject-ca-from"
+)
+
+// genericManagers are field managers that tell nothing about who owns
+// an object, they are skipped when guessing its owner.
+var genericManagers = map[string]bool{
+	"kubectl":                   true,
+	"kubectl-client-side-apply": true,
+	"kubectl-create":            true,
+	"kubectl-edit":              true,
+	"kubectl-patch":             true,
+	"kube-apiserver":            true,
+	"before-first-apply":        true,
+}
+
+// provenance extracts who created and manages the configuration with
+// the given metadata.
+func provenance(meta metaV1.ObjectMeta) *printer.PrintProvenanceItem {
+	p := &printer.PrintProvenanceItem{
+		CreationTimestamp: meta.CreationTimestamp.Time,
+		Generation:        meta.Generation,
+		HelmRelease:       meta.Annotations[helmReleaseNameAnnotation],
+		HelmNamespace:     meta.Annotations[helmReleaseNamespaceAnnotation],
+		ManagedBy:         meta.Labels[managedByLabel],
+		InjectCAFrom:      meta.Annotations[injectCAFromAnnotation],
+	}
+
+	seen := map[string]bool{}
+	for _, mf := range meta.ManagedFields {
+		if !seen[mf.Manager] {
+			seen[mf.Manager] = true
+			p.Managers = append(p.Managers, mf.Manager)
+		}
+	}
+	sort.Strings(p.Managers)
+
+	for _, ref := range meta.OwnerReferences {
+		p.OwnerReferences = append(p.OwnerReferences, fmt.Sprintf("%s/%s", ref.Kind, ref.Name))
+	}
+
+	p.Owner = owner(p)
+	return p
+}
+
+// owner picks the most specific hint of who to contact about a
+// configuration: its Helm release, owner, managed-by label or field manager.
+func owner(p *printer.PrintProvenanceItem) string {
+	switch {
+	case p.HelmRelease != "" && p.HelmNamespace != "":
+		return fmt.Sprintf("helm:%s/%s", p.HelmNamespace, p.HelmRelease)
+	case p.HelmRelease != "":
+		return "helm:" + p.HelmRelease
+	case len(p.OwnerReferences) > 0:
+		return p.OwnerReferences[0]
+	case p.ManagedBy != "":
+		return p.ManagedBy
+	}
+	for _, m := range p.Managers {
+		if !genericManagers[m] {
+			return m
+		}
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestProvenanceOwner(t *testing.T) {
+	managers := []metaV1.ManagedFieldsEntry{{Manager: "kubectl-client-side-apply"}, {Manager: "istio-operator"}}
+
+	tests := []struct {
+		name string
+		meta metaV1.ObjectMeta
+		want string
+	}{
+		{
+			name: "helm release and namespace",
+			meta: metaV1.ObjectMeta{
+				Annotations: map[string]string{helmReleaseNameAnnotation: "gatekeeper", helmReleaseNamespaceAnnotation: "gatekeeper-system"},
+				Labels:      map[string]string{managedByLabel: "Helm"},
+			},
+			want: "helm:gatekeeper-system/gatekeeper",
+		},
+		{
+			name: "helm release",
+			meta: metaV1.ObjectMeta{Annotations: map[string]string{helmReleaseNameAnnotation: "gatekeeper"}},
+			want: "helm:gatekeeper",
+		},
+		{
+			name: "owner reference",
+			meta: metaV1.ObjectMeta{
+				OwnerReferences: []metaV1.OwnerReference{{Kind: "Deployment", Name: "operator"}},
+				Labels:          map[string]string{managedByLabel: "operator"},
+			},
+			want: "Deployment/operator",
+		},
+		{
+			name: "managed-by label",
+			meta: metaV1.ObjectMeta{Labels: map[string]string{managedByLabel: "kustomize"}, ManagedFields: managers},
+			want: "kustomize",
+		},
+		{
+			name: "first specific field manager",
+			meta: metaV1.ObjectMeta{ManagedFields: managers},
+			want: "istio-operator",
+		},
+		{
+			name: "generic field managers only",
+			meta: metaV1.ObjectMeta{ManagedFields: managers[:1]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := provenance(tt.meta).Owner; got != tt.want {
+				t.Errorf("owner = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"crypto/x509"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"sort"
+)
+
+// servingCert is the tls.crt of a secret mounted by the pods of a service.
+type servingCert struct {
+	name  string
+	pod   string
+	certs [][]byte
+}
+
+// servingSecrets locates the TLS secrets mounted by the pods behind the
+// given service and verifies their tls.crt against the given CABundle and
+// the service's DNS name, as the API server does when calling the webhook.
+func (w *WebHookClient) servingSecrets(service printer.PrintServiceItem, bundle []byte) []printer.PrintServingSecretItem {
+	key := service.Namespace + "/" + service.Name
+	if w.servingCerts == nil {
+		w.servingCerts = map[string][]servingCert{}
+	}
+	mounted, ok := w.servingCerts[key]
+	if !ok {
+		mounted = w.fetchServingCerts(service)
+		w.servingCerts[key] = mounted
+	}
+
+	roots, _, rootsErr := parseCABundle(bundle)
+	serverName := fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)
+
+	var items []printer.PrintServingSecretItem
+	for _, m := range mounted {
+		item := printer.PrintServingSecretItem{
+			Name: m.name,
+			Pod:  m.pod,
+		}
+		leaf, err := x509.ParseCertificate(m.certs[0])
+		if err != nil {
+			item.VerifyError = fmt.Sprintf("invalid tls.crt: %v", err)
+			items = append(items, item)
+			continue
+		}
+		item.NotAfter = leaf.NotAfter
+		item.DNSNames = leaf.DNSNames
+
+		if rootsErr != nil {
+			item.VerifyError = rootsErr.Error()
+			items = append(items, item)
+			continue
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, der := range m.certs[1:] {
+			if c, err := x509.ParseCertificate(der); err == nil {
+				intermediates.AddCert(c)
+			}
+		}
+		_, err = leaf.Verify(x509.VerifyOptions{
+			DNSName:       serverName,
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		if err != nil {
+			item.VerifyError = err.Error()
+		} else {
+			item.Verified = true
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// fetchServingCerts reads the kubernetes.io/tls secrets mounted by the
+// pods behind the given service.
+func (w *WebHookClient) fetchServingCerts(service printer.PrintServiceItem) []servingCert {
+	pods, err := w.servicePods(service)
+	if err != nil || len(pods) == 0 {
+		return nil
+	}
+
+	// the pods of a service usually share their secrets, so every secret
+	// is reported once with the first pod mounting it
+	mounted := map[string]string{}
+	for _, pod := range pods {
+		for _, name := range podSecrets(pod) {
+			if _, ok := mounted[name]; !ok {
+				mounted[name] = pod.Name
+			}
+		}
+	}
+
+	// only TLS secrets are listed, the others, such as credentials, are
+	// never read
+	secrets, err := w.client.CoreV1().Secrets(service.Namespace).List(w.context, metaV1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("type", string(coreV1.SecretTypeTLS)).String(),
+	})
+	if err != nil {
+		return nil
+	}
+
+	var result []servingCert
+	for _, secret := range secrets.Items {
+		pod, ok := mounted[secret.Name]
+		if !ok || secret.Type != coreV1.SecretTypeTLS {
+			continue
+		}
+		certs := pemBlocks(secret.Data[coreV1.TLSCertKey])
+		if len(certs) == 0 {
+			continue
+		}
+		result = append(result, servingCert{name: secret.Name, pod: pod, certs: certs})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+// servicePods returns the pods behind the given service, those of its
+// endpoints when known and those matching its selector otherwise.
+func (w *WebHookClient) servicePods(service printer.PrintServiceItem) ([]coreV1.Pod, error) {
+	var pods []coreV1.Pod
+	seen := map[string]bool{}
+	for _, ep := range service.Endpoints {
+		if ep.Pod == "" || seen[ep.Pod] {
+			continue
+		}
+		seen[ep.Pod] = true
+		pod, err := w.client.CoreV1().Pods(service.Namespace).Get(w.context, ep.Pod, metaV1.GetOptions{})
+		if err == nil {
+			pods = append(pods, *pod)
+		}
+	}
+	if len(pods) > 0 || len(service.Selector) == 0 {
+		return pods, nil
+	}
+
+	list, err := w.client.CoreV1().Pods(service.Namespace).List(w.context, metaV1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(service.Selector).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// podSecrets returns the names of the secrets the given pod mounts,
+// directly or through projected volumes.
+func podSecrets(pod coreV1.Pod) []string {
+	var names []string
+	for _, v := range pod.Spec.Volumes {
+		if v.Secret != nil {
+			names = append(names, v.Secret.SecretName)
+		}
+		if v.Projected != nil {
+			for _, source := range v.Projected.Sources {
+				if source.Secret != nil {
+					names = append(names, source.Secret.Name)
+				}
+			}
+		}
+	}
+	return names
+}
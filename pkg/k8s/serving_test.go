@@ -0,0 +1,142 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"strings"
+	"testing"
+)
+
+// pod returns a pod labelled app=webhook mounting the given secrets, the
+// first one directly and the others through a projected volume.
+func pod(name string, secrets ...string) *coreV1.Pod {
+	p := &coreV1.Pod{ObjectMeta: metaV1.ObjectMeta{Name: name, Namespace: "webhooks", Labels: map[string]string{"app": "webhook"}}}
+	for i, s := range secrets {
+		if i == 0 {
+			p.Spec.Volumes = append(p.Spec.Volumes, coreV1.Volume{
+				Name:         s,
+				VolumeSource: coreV1.VolumeSource{Secret: &coreV1.SecretVolumeSource{SecretName: s}},
+			})
+			continue
+		}
+		p.Spec.Volumes = append(p.Spec.Volumes, coreV1.Volume{
+			Name: s,
+			VolumeSource: coreV1.VolumeSource{Projected: &coreV1.ProjectedVolumeSource{Sources: []coreV1.VolumeProjection{{
+				Secret: &coreV1.SecretProjection{LocalObjectReference: coreV1.LocalObjectReference{Name: s}},
+			}}}},
+		})
+	}
+	return p
+}
+
+func TestServingSecrets(t *testing.T) {
+	ca, other := newTestCA(t, "webhook-ca"), newTestCA(t, "other-ca")
+	service := printer.PrintServiceItem{Name: "webhook", Namespace: "webhooks", Selector: map[string]string{"app": "webhook"}}
+
+	// secrets that are not of the kubernetes.io/tls type are never read,
+	// whatever their keys
+	opaque := secret("opaque-tls", map[string][]byte{"tls.crt": ca.issue(t, "webhook.webhooks.svc")})
+	opaque.Type = coreV1.SecretTypeOpaque
+	objects := []runtime.Object{
+		secret("trusted-tls", map[string][]byte{"tls.crt": ca.issue(t, "webhook.webhooks.svc")}),
+		secret("untrusted-tls", map[string][]byte{"tls.crt": other.issue(t, "webhook.webhooks.svc")}),
+		secret("misnamed-tls", map[string][]byte{"tls.crt": ca.issue(t, "webhook.default.svc")}),
+		opaque,
+		pod("webhook-0", "trusted-tls", "opaque-tls"),
+		pod("webhook-1", "trusted-tls", "untrusted-tls"),
+		pod("webhook-2", "misnamed-tls"),
+	}
+
+	tests := []struct {
+		name      string
+		endpoints []printer.PrintEndpointItem
+		bundle    []byte
+		want      []string
+	}{
+		{
+			name:   "pods of the selector",
+			bundle: ca.pem,
+			want: []string{
+				"misnamed-tls@webhook-2: x509: certificate is valid for webhook.default.svc, not webhook.webhooks.svc",
+				"trusted-tls@webhook-0: verified",
+				"untrusted-tls@webhook-1: x509: certificate signed by unknown authority",
+			},
+		},
+		{
+			name:      "pods of the endpoints",
+			endpoints: []printer.PrintEndpointItem{{IP: "10.0.0.2", Pod: "webhook-1"}},
+			bundle:    ca.pem,
+			want: []string{
+				"trusted-tls@webhook-1: verified",
+				"untrusted-tls@webhook-1: x509: certificate signed by unknown authority",
+			},
+		},
+		{
+			name:      "bundle of another CA",
+			endpoints: []printer.PrintEndpointItem{{IP: "10.0.0.1", Pod: "webhook-0"}},
+			bundle:    other.pem,
+			want:      []string{"trusted-tls@webhook-0: x509: certificate signed by unknown authority"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the certificates are cached per service, so every case
+			// starts from a new client
+			w := NewWebHookClient(fake.NewSimpleClientset(objects...))
+			s := service
+			s.Endpoints = tt.endpoints
+
+			var got []string
+			for _, item := range w.servingSecrets(s, tt.bundle) {
+				result := "verified"
+				if !item.Verified {
+					result = item.VerifyError
+				}
+				got = append(got, item.Name+"@"+item.Pod+": "+result)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestServingSecretsCache(t *testing.T) {
+	ca := newTestCA(t, "webhook-ca")
+	client := fake.NewSimpleClientset(
+		secret("trusted-tls", map[string][]byte{"tls.crt": ca.issue(t, "webhook.webhooks.svc")}),
+		pod("webhook-0", "trusted-tls"))
+	w := NewWebHookClient(client)
+	service := printer.PrintServiceItem{Name: "webhook", Namespace: "webhooks", Selector: map[string]string{"app": "webhook"}}
+
+	if got := w.servingSecrets(service, ca.pem); len(got) != 1 {
+		t.Fatalf("got %d serving secrets, want 1", len(got))
+	}
+	if err := client.CoreV1().Secrets("webhooks").Delete(context.TODO(), "trusted-tls", metaV1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.servingSecrets(service, ca.pem); len(got) != 1 || !got[0].Verified {
+		t.Errorf("got %+v, want the cached serving secret of the service", got)
+	}
+}
@@ -18,79 +18,221 @@ package k8s
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/pem"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/matchcondition"
 	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
-	"k8s.io/api/admissionregistration/v1beta1"
+	admissionV1 "k8s.io/api/admissionregistration/v1"
+	coreV1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	typedV1beta1 "k8s.io/client-go/kubernetes/typed/admissionregistration/v1beta1"
+	typedAdmissionV1 "k8s.io/client-go/kubernetes/typed/admissionregistration/v1"
 	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
-	"log"
 	"time"
 )
 
 type WebHookClient struct {
-	client  *kubernetes.Clientset
-	wClient typedV1beta1.MutatingWebhookConfigurationInterface
-	vClient typedV1beta1.ValidatingWebhookConfigurationInterface
+	client  kubernetes.Interface
+	wClient typedAdmissionV1.MutatingWebhookConfigurationInterface
+	vClient typedAdmissionV1.ValidatingWebhookConfigurationInterface
 	nClient typedCoreV1.NamespaceInterface
 	context context.Context
+	prober  *Prober
+	dynamic dynamic.Interface
+	events  bool
+	// failuresSince is how far back failure events are counted, they are
+	// not when it is zero.
+	failuresSince time.Duration
+	// secrets enables reading the serving secrets of webhook pods and the
+	// secrets cert-manager injects CABundles from.
+	secrets bool
+
+	namespaces []coreV1.Namespace
+	// servingCerts are the serving certificates mounted by the pods of a
+	// service, by namespace/name, as several webhooks share a service.
+	servingCerts map[string][]servingCert
+	// certificateGVR is the cert-manager Certificate resource served by
+	// the cluster, looked up on first use.
+	certificateGVR        *schema.GroupVersionResource
+	certificateErr        error
+	certificateDiscovered bool
 }
 
 // NewWebHookClient constructs a new WebHookClient with the specified output
-// of *kubernetes.Clientset
-func NewWebHookClient(client *kubernetes.Clientset) *WebHookClient {
+// of kubernetes.Interface
+func NewWebHookClient(client kubernetes.Interface) *WebHookClient {
 	return &WebHookClient{
 		client:  client,
-		wClient: client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations(),
-		vClient: client.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations(),
+		wClient: client.AdmissionregistrationV1().MutatingWebhookConfigurations(),
+		vClient: client.AdmissionregistrationV1().ValidatingWebhookConfigurations(),
 		nClient: client.CoreV1().Namespaces(),
 		context: context.Background(),
 	}
 }
 
+// SetProber enables probing the TLS endpoint of every webhook with the
+// given Prober.
+func (w *WebHookClient) SetProber(p *Prober) {
+	w.prober = p
+}
+
+// SetSecrets enables reading the TLS secrets mounted by the pods of every
+// webhook service and the secrets cert-manager injects CABundles from,
+// which needs permission to read secrets.
+func (w *WebHookClient) SetSecrets(enabled bool) {
+	w.secrets = enabled
+}
+
+// SetDynamicClient enables reading custom resources, such as the
+// cert-manager Certificates CABundles are injected from.
+func (w *WebHookClient) SetDynamicClient(d dynamic.Interface) {
+	w.dynamic = d
+}
+
 type Resource struct {
 	Name       string
 	Operations []string
 }
 
-// Run
-//args[0]: self executable
-//args[1]: may be 'webhookname' or '--kubeconfig'
-func (w *WebHookClient) Run(args []string) (*printer.PrintModel, error) {
-	var items []printer.PrintItem
+// Configurations holds the raw webhook configurations a PrintModel is
+// built from.
+type Configurations struct {
+	Mutating   []admissionV1.MutatingWebhookConfiguration   `json:"mutating,omitempty"`
+	Validating []admissionV1.ValidatingWebhookConfiguration `json:"validating,omitempty"`
+	// Admission policies and their bindings are kept unstructured as they
+	// are served in more versions than the typed clients in use cover.
+	ValidatingPolicies       []unstructured.Unstructured `json:"validatingPolicies,omitempty"`
+	ValidatingPolicyBindings []unstructured.Unstructured `json:"validatingPolicyBindings,omitempty"`
+	MutatingPolicies         []unstructured.Unstructured `json:"mutatingPolicies,omitempty"`
+	MutatingPolicyBindings   []unstructured.Unstructured `json:"mutatingPolicyBindings,omitempty"`
+	// Warnings tell what could not be fetched and is left out.
+	Warnings []string `json:"-"`
+}
 
-	if len(args) == 0 {
-		mutatingWebhookConfigurationList, _ := w.wClient.List(w.context, metaV1.ListOptions{})
+// Object returns the raw configuration or policy the given item was built
+// from, nil if it is not among the Configurations.
+func (c *Configurations) Object(item printer.PrintItem) interface{} {
+	switch {
+	case item.Policy != nil:
+		policies := c.ValidatingPolicies
+		if item.Kind == "Mutating" {
+			policies = c.MutatingPolicies
+		}
+		for _, p := range policies {
+			if p.GetName() == item.Name {
+				return p.Object
+			}
+		}
+	case item.Kind == "Mutating":
+		for _, mwc := range c.Mutating {
+			if mwc.Name == item.Name {
+				mwc.APIVersion, mwc.Kind = admissionV1.SchemeGroupVersion.String(), "MutatingWebhookConfiguration"
+				return mwc
+			}
+		}
+	case item.Kind == "Validating":
+		for _, vwc := range c.Validating {
+			if vwc.Name == item.Name {
+				vwc.APIVersion, vwc.Kind = admissionV1.SchemeGroupVersion.String(), "ValidatingWebhookConfiguration"
+				return vwc
+			}
+		}
+	}
+	return nil
+}
 
-		validatingWebhookConfigurationList, _ := w.vClient.List(w.context, metaV1.ListOptions{})
+// Fetch returns every webhook configuration, or only the ones named
+// args[0] when given.
+func (w *WebHookClient) Fetch(args []string) (*Configurations, error) {
+	result := &Configurations{}
 
-		for _, mwc := range mutatingWebhookConfigurationList.Items {
-			w.fillMutatingWebhookConfigurations(mwc, &items)
+	if len(args) == 0 {
+		mutatingWebhookConfigurationList, err := w.wClient.List(w.context, metaV1.ListOptions{})
+		if err != nil {
+			return nil, err
 		}
-		for _, mwc := range validatingWebhookConfigurationList.Items {
-			w.fillValidatingWebhookConfigurations(mwc, &items)
+
+		validatingWebhookConfigurationList, err := w.vClient.List(w.context, metaV1.ListOptions{})
+		if err != nil {
+			return nil, err
 		}
+
+		result.Mutating = mutatingWebhookConfigurationList.Items
+		result.Validating = validatingWebhookConfigurationList.Items
 	} else {
-		mutatingWebhookConfiguration, _ := w.wClient.Get(w.context, args[0], metaV1.GetOptions{})
+		mutatingWebhookConfiguration, err := w.wClient.Get(w.context, args[0], metaV1.GetOptions{})
+		if err == nil {
+			result.Mutating = append(result.Mutating, *mutatingWebhookConfiguration)
+		} else if !apiErrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		validatingWebhookConfiguration, err := w.vClient.Get(w.context, args[0], metaV1.GetOptions{})
+		if err == nil {
+			result.Validating = append(result.Validating, *validatingWebhookConfiguration)
+		} else if !apiErrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	for _, p := range []struct {
+		resource, bindingResource string
+		policies, bindings        *[]unstructured.Unstructured
+	}{
+		{validatingPolicyResource, validatingPolicyBindingResource, &result.ValidatingPolicies, &result.ValidatingPolicyBindings},
+		{mutatingPolicyResource, mutatingPolicyBindingResource, &result.MutatingPolicies, &result.MutatingPolicyBindings},
+	} {
+		for _, policy := range w.fetchPolicies(p.resource, &result.Warnings) {
+			if len(args) == 0 || policy.GetName() == args[0] {
+				*p.policies = append(*p.policies, policy)
+			}
+		}
+		*p.bindings = w.fetchPolicies(p.bindingResource, &result.Warnings)
+	}
 
-		validatingWebhookConfiguration, _ := w.vClient.Get(w.context, args[0], metaV1.GetOptions{})
+	return result, nil
+}
 
-		w.fillMutatingWebhookConfigurations(*mutatingWebhookConfiguration, &items)
-		w.fillValidatingWebhookConfigurations(*validatingWebhookConfiguration, &items)
+// Build analyses the given webhook configurations into a PrintModel.
+func (w *WebHookClient) Build(configurations *Configurations) *printer.PrintModel {
+	var items []printer.PrintItem
+
+	for _, mwc := range configurations.Mutating {
+		w.fillMutatingWebhookConfigurations(mwc, &items)
+	}
+	for _, mp := range configurations.MutatingPolicies {
+		w.fillPolicies("Mutating", mp, configurations.MutatingPolicyBindings, &items)
+	}
+	for _, mwc := range configurations.Validating {
+		w.fillValidatingWebhookConfigurations(mwc, &items)
+	}
+	for _, vap := range configurations.ValidatingPolicies {
+		w.fillPolicies("Policy", vap, configurations.ValidatingPolicyBindings, &items)
+	}
+
+	if w.events || w.failuresSince > 0 {
+		w.fillEvents(items)
 	}
 
 	return &printer.PrintModel{
 		Items: items,
-	}, nil
+	}
 }
 
-func (w *WebHookClient) fillMutatingWebhookConfigurations(mwc v1beta1.MutatingWebhookConfiguration, items *[]printer.PrintItem) {
+func (w *WebHookClient) fillMutatingWebhookConfigurations(mwc admissionV1.MutatingWebhookConfiguration, items *[]printer.PrintItem) {
 	item := printer.PrintItem{
-		Kind: "Mutating",
-		Name: mwc.Name, //TODO: typeMeta nil
+		Kind:       "Mutating",
+		Name:       mwc.Name, //TODO: typeMeta nil
+		Provenance: provenance(mwc.ObjectMeta),
 	}
+	caSource := w.caInjection(mwc.ObjectMeta)
 
 	for _, webhook := range mwc.Webhooks {
 		var activeNamespaces []string
@@ -98,27 +240,52 @@ func (w *WebHookClient) fillMutatingWebhookConfigurations(mwc v1beta1.MutatingWe
 
 		webhookItem := printer.PrintWebhookItem{
 			Name: webhook.Name,
+			URL:  webhook.ClientConfig.URL,
 		}
 
 		if webhook.ClientConfig.Service != nil {
 			ss := w.GenerateServiceItem(webhook.ClientConfig.Service.Namespace, webhook.ClientConfig.Service.Name, webhook.ClientConfig.Service.Path, webhook.ClientConfig.Service.Port)
+			if ss.Found && w.secrets {
+				ss.ServingSecrets = w.servingSecrets(ss, webhook.ClientConfig.CABundle)
+			}
 			webhookItem.Service = ss
 		}
 
+		if w.prober != nil {
+			webhookItem.Probe = w.prober.Probe(webhook.ClientConfig)
+		}
+
 		item.Webhook = webhookItem
 		resources := w.fillRulesForMutating(webhook)
 
 		item.ResourceModels = resources
 		item.ValidUntil = retrieveValidDateCount(webhook.ClientConfig.CABundle)
+		item.CABundleFingerprint = fingerprint(webhook.ClientConfig.CABundle)
+		item.CABundleIssuer = issuer(webhook.ClientConfig.CABundle)
+		item.CABundleCertificates = certificates(webhook.ClientConfig.CABundle)
+		if caSource != nil {
+			item.CAInjection = caSource.Check(webhook.ClientConfig.CABundle)
+		}
 		item.ActiveNamespaces = activeNamespaces
+		item.FailurePolicy = failurePolicy(webhook.FailurePolicy)
+		item.TimeoutSeconds = webhook.TimeoutSeconds
+		item.NamespaceSelector = webhook.NamespaceSelector
+		item.ObjectSelector = webhook.ObjectSelector
+		item.MatchPolicy = matchPolicy(webhook.MatchPolicy)
+		item.SideEffects = sideEffects(webhook.SideEffects)
+		item.AdmissionReviewVersions = webhook.AdmissionReviewVersions
+		item.ReinvocationPolicy = reinvocationPolicy(webhook.ReinvocationPolicy)
+		item.MatchConditions = matchConditionItems(webhook.MatchConditions)
 		*items = append(*items, item)
 	}
 }
-func (w *WebHookClient) fillValidatingWebhookConfigurations(mwc v1beta1.ValidatingWebhookConfiguration, items *[]printer.PrintItem) {
+func (w *WebHookClient) fillValidatingWebhookConfigurations(mwc admissionV1.ValidatingWebhookConfiguration, items *[]printer.PrintItem) {
 	item := printer.PrintItem{
-		Kind: "Validating",
-		Name: mwc.Name, //TODO: typeMeta nil
+		Kind:       "Validating",
+		Name:       mwc.Name, //TODO: typeMeta nil
+		Provenance: provenance(mwc.ObjectMeta),
 	}
+	caSource := w.caInjection(mwc.ObjectMeta)
 
 	for _, webhook := range mwc.Webhooks {
 		var activeNamespaces []string
@@ -126,23 +293,45 @@ func (w *WebHookClient) fillValidatingWebhookConfigurations(mwc v1beta1.Validati
 
 		webhookItem := printer.PrintWebhookItem{
 			Name: webhook.Name,
+			URL:  webhook.ClientConfig.URL,
 		}
 
 		if webhook.ClientConfig.Service != nil {
 			ss := w.GenerateServiceItem(webhook.ClientConfig.Service.Namespace, webhook.ClientConfig.Service.Name, webhook.ClientConfig.Service.Path, webhook.ClientConfig.Service.Port)
+			if ss.Found && w.secrets {
+				ss.ServingSecrets = w.servingSecrets(ss, webhook.ClientConfig.CABundle)
+			}
 			webhookItem.Service = ss
 		}
 
+		if w.prober != nil {
+			webhookItem.Probe = w.prober.Probe(webhook.ClientConfig)
+		}
+
 		item.Webhook = webhookItem
 		resources := w.fillRulesForValidating(webhook)
 
 		item.ResourceModels = resources
 		item.ValidUntil = retrieveValidDateCount(webhook.ClientConfig.CABundle)
+		item.CABundleFingerprint = fingerprint(webhook.ClientConfig.CABundle)
+		item.CABundleIssuer = issuer(webhook.ClientConfig.CABundle)
+		item.CABundleCertificates = certificates(webhook.ClientConfig.CABundle)
+		if caSource != nil {
+			item.CAInjection = caSource.Check(webhook.ClientConfig.CABundle)
+		}
 		item.ActiveNamespaces = activeNamespaces
+		item.FailurePolicy = failurePolicy(webhook.FailurePolicy)
+		item.TimeoutSeconds = webhook.TimeoutSeconds
+		item.NamespaceSelector = webhook.NamespaceSelector
+		item.ObjectSelector = webhook.ObjectSelector
+		item.MatchPolicy = matchPolicy(webhook.MatchPolicy)
+		item.SideEffects = sideEffects(webhook.SideEffects)
+		item.AdmissionReviewVersions = webhook.AdmissionReviewVersions
+		item.MatchConditions = matchConditionItems(webhook.MatchConditions)
 		*items = append(*items, item)
 	}
 }
-func (w *WebHookClient) fillRulesForMutating(webhook v1beta1.MutatingWebhook) []printer.ResourceModel {
+func (w *WebHookClient) fillRulesForMutating(webhook admissionV1.MutatingWebhook) []printer.ResourceModel {
 	var resources []printer.ResourceModel
 
 	for _, rule := range webhook.Rules {
@@ -155,67 +344,72 @@ func (w *WebHookClient) fillRulesForMutating(webhook v1beta1.MutatingWebhook) []
 		rs = append(rs, rule.Resources...)
 
 		resources = append(resources, printer.ResourceModel{
-			Operations: ops,
-			Resources:  rs,
+			APIGroups:   rule.APIGroups,
+			APIVersions: rule.APIVersions,
+			Operations:  ops,
+			Resources:   rs,
+			Scope:       ruleScope(rule.Scope),
 		})
 	}
 	return resources
 }
-func (w *WebHookClient) fillRulesForValidating(webhook v1beta1.ValidatingWebhook) []printer.ResourceModel {
+func (w *WebHookClient) fillRulesForValidating(webhook admissionV1.ValidatingWebhook) []printer.ResourceModel {
 	var resources []printer.ResourceModel
-	var ops, rs []string
+
 	for _, rule := range webhook.Rules {
+		var ops, rs []string
 
 		for _, op := range rule.Operations {
-			ops = append(rs, string(op))
+			ops = append(ops, string(op))
 		}
 
 		rs = append(rs, rule.Resources...)
 
 		resources = append(resources, printer.ResourceModel{
-			Operations: ops,
-			Resources:  rs,
+			APIGroups:   rule.APIGroups,
+			APIVersions: rule.APIVersions,
+			Operations:  ops,
+			Resources:   rs,
+			Scope:       ruleScope(rule.Scope),
 		})
 	}
 	return resources
 }
-func (w *WebHookClient) fillActiveNamespacesForMutating(webhook v1beta1.MutatingWebhook, activeNamespaces *[]string) {
-	if webhook.NamespaceSelector != nil {
-		ncList, _ := w.nClient.List(w.context, metaV1.ListOptions{})
-
-		if ncList != nil {
-			for _, ns := range ncList.Items {
-				available := false
-				for k, v := range webhook.NamespaceSelector.MatchLabels {
-					if ns.Labels[k] == v {
-						available = true
-					}
-				}
-				if available {
-					*activeNamespaces = append(*activeNamespaces, ns.Name)
-				}
-			}
+func (w *WebHookClient) fillActiveNamespacesForMutating(webhook admissionV1.MutatingWebhook, activeNamespaces *[]string) {
+	*activeNamespaces = append(*activeNamespaces, w.matchNamespaces(webhook.NamespaceSelector)...)
+}
+func (w *WebHookClient) fillActiveNamespacesForValidating(webhook admissionV1.ValidatingWebhook, activeNamespaces *[]string) {
+	*activeNamespaces = append(*activeNamespaces, w.matchNamespaces(webhook.NamespaceSelector)...)
+}
+
+// matchNamespaces returns the names of the namespaces the given
+// namespaceSelector selects. A nil selector selects every namespace,
+// as the API server defaults it to an empty one.
+func (w *WebHookClient) matchNamespaces(namespaceSelector *metaV1.LabelSelector) []string {
+	selector := labels.Everything()
+	if namespaceSelector != nil {
+		s, err := metaV1.LabelSelectorAsSelector(namespaceSelector)
+		if err != nil {
+			return nil
 		}
+		selector = s
 	}
-}
-func (w *WebHookClient) fillActiveNamespacesForValidating(webhook v1beta1.ValidatingWebhook, activeNamespaces *[]string) {
-	if webhook.NamespaceSelector != nil {
-		ncList, _ := w.nClient.List(w.context, metaV1.ListOptions{})
-
-		if ncList != nil {
-			for _, ns := range ncList.Items {
-				available := false
-				for k, v := range webhook.NamespaceSelector.MatchLabels {
-					if ns.Labels[k] == v {
-						available = true
-					}
-				}
-				if available {
-					*activeNamespaces = append(*activeNamespaces, ns.Name)
-				}
-			}
+
+	if w.namespaces == nil {
+		ncList, err := w.nClient.List(w.context, metaV1.ListOptions{})
+		if err != nil {
+			return nil
+		}
+		w.namespaces = ncList.Items
+	}
+
+	var names []string
+	for _, ns := range w.namespaces {
+		if selector.Matches(labels.Set(ns.Labels)) {
+			names = append(names, ns.Name)
 		}
 	}
+	return names
 }
 
 func (w *WebHookClient) GenerateServiceItem(ns, name string, path *string, port *int32) printer.PrintServiceItem {
@@ -227,6 +421,7 @@ func (w *WebHookClient) GenerateServiceItem(ns, name string, path *string, port
 		Name:      name,
 		Namespace: ns,
 		Path:      path,
+		Port:      port,
 		Ports:     nil,
 	}
 
@@ -246,20 +441,145 @@ func (w *WebHookClient) GenerateServiceItem(ns, name string, path *string, port
 	result.Found = true
 	result.ClusterIP = ss.Spec.ClusterIP
 	result.Type = string(ss.Spec.Type)
+	result.Selector = ss.Spec.Selector
+
+	endpoints, err := w.client.CoreV1().Endpoints(ns).Get(w.context, name, metaV1.GetOptions{})
+	if err == nil {
+		for _, subset := range endpoints.Subsets {
+			result.Endpoints = append(result.Endpoints, endpointItems(subset.Addresses, true)...)
+			result.Endpoints = append(result.Endpoints, endpointItems(subset.NotReadyAddresses, false)...)
+		}
+	}
 
 	return result
 }
 
+func endpointItems(addresses []coreV1.EndpointAddress, ready bool) []printer.PrintEndpointItem {
+	var items []printer.PrintEndpointItem
+	for _, addr := range addresses {
+		item := printer.PrintEndpointItem{
+			IP:    addr.IP,
+			Ready: ready,
+		}
+		if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
+			item.Pod = addr.TargetRef.Name
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// matchConditionItems compiles the given matchConditions, recording the
+// error of those that do not.
+func matchConditionItems(conditions []admissionV1.MatchCondition) []printer.PrintMatchConditionItem {
+	var items []printer.PrintMatchConditionItem
+	for _, c := range conditions {
+		item := printer.PrintMatchConditionItem{
+			Name:       c.Name,
+			Expression: c.Expression,
+		}
+		if _, err := matchcondition.Compile(c.Expression); err != nil {
+			item.Error = err.Error()
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// fingerprint returns the SHA-256 fingerprint of the given CABundle.
+func fingerprint(bundle []byte) string {
+	if len(bundle) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(bundle))
+}
+
+// issuer returns the issuer of the first certificate in the given CABundle.
+func issuer(bundle []byte) string {
+	block, _ := pem.Decode(bundle)
+	if block == nil {
+		return ""
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return ""
+	}
+	return cert.Issuer.String()
+}
+
+// certificates decodes the certificates of the given CABundle, skipping
+// the ones that cannot be parsed.
+func certificates(bundle []byte) []printer.PrintCertificateItem {
+	var items []printer.PrintCertificateItem
+	for _, block := range pemBlocks(bundle) {
+		cert, err := x509.ParseCertificate(block)
+		if err != nil {
+			continue
+		}
+		items = append(items, printer.PrintCertificateItem{
+			Subject:      cert.Subject.String(),
+			Issuer:       cert.Issuer.String(),
+			SerialNumber: cert.SerialNumber.String(),
+			NotBefore:    cert.NotBefore,
+			NotAfter:     cert.NotAfter,
+			DNSNames:     cert.DNSNames,
+			IsCA:         cert.IsCA,
+		})
+	}
+	return items
+}
+
+// sideEffects returns the given sideEffects, falling back to Unknown,
+// which v1 only accepts from configurations created through admissionV1.
+func sideEffects(effects *admissionV1.SideEffectClass) string {
+	if effects == nil {
+		return string(admissionV1.SideEffectClassUnknown)
+	}
+	return string(*effects)
+}
+
+// reinvocationPolicy returns the given reinvocationPolicy, falling back
+// to the default of Never.
+func reinvocationPolicy(policy *admissionV1.ReinvocationPolicyType) string {
+	if policy == nil {
+		return string(admissionV1.NeverReinvocationPolicy)
+	}
+	return string(*policy)
+}
+
+// failurePolicy returns the given failurePolicy, falling back to the
+// v1 default of Fail.
+func failurePolicy(policy *admissionV1.FailurePolicyType) string {
+	if policy == nil {
+		return string(admissionV1.Fail)
+	}
+	return string(*policy)
+}
+
+// matchPolicy returns the given matchPolicy, falling back to the
+// v1 default of Equivalent.
+func matchPolicy(policy *admissionV1.MatchPolicyType) string {
+	if policy == nil {
+		return string(admissionV1.Equivalent)
+	}
+	return string(*policy)
+}
+
+// ruleScope returns the given rule scope, falling back to the default of "*".
+func ruleScope(scope *admissionV1.ScopeType) string {
+	if scope == nil {
+		return string(admissionV1.AllScopes)
+	}
+	return string(*scope)
+}
+
 //retrieveValidDateCount returns remaining time of the given
 //webhook's CABundle certificate.
+//Bundles that hold no parsable certificate have none left.
 func retrieveValidDateCount(certificate []byte) time.Duration {
-	if certificate == nil {
+	certs := certificates(certificate)
+	if len(certs) == 0 {
 		return 0
 	}
-	block, _ := pem.Decode(certificate)
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		log.Fatalf("x509.ParseCertificate - error occurred, detail: %v", err)
-	}
-	return time.Until(cert.NotAfter)
+	return time.Until(certs[0].NotAfter)
 }
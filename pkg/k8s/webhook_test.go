@@ -0,0 +1,106 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k8s
+
+import (
+	"context"
+	admissionV1 "k8s.io/api/admissionregistration/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchNamespaces(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		namespace("default", nil),
+		namespace("payments", map[string]string{"team": "payments", "tier": "backend"}),
+		namespace("checkout", map[string]string{"team": "payments", "tier": "frontend"}),
+		namespace("kube-system", map[string]string{"control-plane": "true"}))
+	w := &WebHookClient{
+		nClient: client.CoreV1().Namespaces(),
+		context: context.Background(),
+	}
+
+	tests := []struct {
+		name     string
+		selector *metaV1.LabelSelector
+		want     string
+	}{
+		{
+			name: "nil selector",
+			want: "checkout,default,kube-system,payments",
+		},
+		{
+			name:     "empty selector",
+			selector: &metaV1.LabelSelector{},
+			want:     "checkout,default,kube-system,payments",
+		},
+		{
+			name:     "every label must match",
+			selector: &metaV1.LabelSelector{MatchLabels: map[string]string{"team": "payments", "tier": "backend"}},
+			want:     "payments",
+		},
+		{
+			name: "matchExpressions",
+			selector: &metaV1.LabelSelector{MatchExpressions: []metaV1.LabelSelectorRequirement{
+				{Key: "control-plane", Operator: metaV1.LabelSelectorOpDoesNotExist},
+			}},
+			want: "checkout,default,payments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(w.matchNamespaces(tt.selector), ","); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillRulesForValidating(t *testing.T) {
+	webhook := admissionV1.ValidatingWebhook{
+		Rules: []admissionV1.RuleWithOperations{
+			{
+				Operations: []admissionV1.OperationType{admissionV1.Create},
+				Rule:       admissionV1.Rule{Resources: []string{"pods"}},
+			},
+			{
+				Operations: []admissionV1.OperationType{admissionV1.Update, admissionV1.Delete},
+				Rule:       admissionV1.Rule{Resources: []string{"deployments"}},
+			},
+		},
+	}
+
+	rules := (&WebHookClient{}).fillRulesForValidating(webhook)
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if !reflect.DeepEqual(rules[0].Operations, []string{"CREATE"}) {
+		t.Errorf("first rule operations = %v, want [CREATE]", rules[0].Operations)
+	}
+	if !reflect.DeepEqual(rules[1].Operations, []string{"UPDATE", "DELETE"}) {
+		t.Errorf("second rule operations = %v, want [UPDATE DELETE]", rules[1].Operations)
+	}
+}
+
+func namespace(name string, labels map[string]string) *coreV1.Namespace {
+	return &coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: name, Labels: labels}}
+}
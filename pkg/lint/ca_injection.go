@@ -0,0 +1,91 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+)
+
+// CAInjection flags webhooks whose CABundle cert-manager is expected to
+// inject but which does not match the CA of the referenced Certificate's
+// Secret, or whose Certificate or Secret is missing. Calls to such a
+// webhook fail TLS verification once the stale CA is no longer served.
+type CAInjection struct{}
+
+func (c *CAInjection) Name() string {
+	return "ca-injection"
+}
+
+func (c *CAInjection) Check(item printer.PrintItem) []printer.Finding {
+	injection := item.CAInjection
+	if injection == nil {
+		return nil
+	}
+
+	severity := printer.SeverityWarning
+	if item.FailurePolicy == "Fail" {
+		severity = printer.SeverityCritical
+	}
+
+	var findings []printer.Finding
+	switch injection.Status {
+	case printer.CAInjectionMismatch:
+		findings = append(findings, printer.Finding{
+			Check:    c.Name(),
+			Severity: severity,
+			Message:  fmt.Sprintf("CABundle does not match the CA of secret %s", injection.Secret),
+		})
+	case printer.CAInjectionCertificateMissing:
+		findings = append(findings, printer.Finding{
+			Check:    c.Name(),
+			Severity: severity,
+			Message:  fmt.Sprintf("certificate %s the CABundle is injected from does not exist", injection.Certificate),
+		})
+	case printer.CAInjectionSecretMissing:
+		findings = append(findings, printer.Finding{
+			Check:    c.Name(),
+			Severity: severity,
+			Message:  fmt.Sprintf("secret %s the CABundle is injected from does not exist", injection.Secret),
+		})
+	case printer.CAInjectionError:
+		findings = append(findings, printer.Finding{
+			Check:    c.Name(),
+			Severity: printer.SeverityWarning,
+			Message:  fmt.Sprintf("CA injection could not be verified: %s", injection.Error),
+		})
+	}
+
+	if injection.Certificate != "" && injection.Status != printer.CAInjectionCertificateMissing {
+		switch {
+		case injection.Renewing:
+			findings = append(findings, printer.Finding{
+				Check:    c.Name(),
+				Severity: printer.SeverityWarning,
+				Message:  fmt.Sprintf("certificate %s is pending renewal, the CABundle may change", injection.Certificate),
+			})
+		case !injection.Ready:
+			findings = append(findings, printer.Finding{
+				Check:    c.Name(),
+				Severity: printer.SeverityWarning,
+				Message:  fmt.Sprintf("certificate %s is not ready", injection.Certificate),
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,94 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"reflect"
+	"testing"
+)
+
+func TestCAInjection(t *testing.T) {
+	finding := func(severity printer.Severity, message string) printer.Finding {
+		return printer.Finding{Check: "ca-injection", Severity: severity, Message: message}
+	}
+
+	tests := []struct {
+		name          string
+		failurePolicy string
+		injection     *printer.PrintCAInjectionItem
+		want          []printer.Finding
+	}{
+		{
+			name: "not injected",
+		},
+		{
+			name:      "in sync",
+			injection: &printer.PrintCAInjectionItem{Certificate: "webhooks/webhook", Secret: "webhooks/webhook-tls", Status: printer.CAInjectionInSync, Ready: true},
+		},
+		{
+			name:          "mismatch failing closed",
+			failurePolicy: "Fail",
+			injection:     &printer.PrintCAInjectionItem{Certificate: "webhooks/webhook", Secret: "webhooks/webhook-tls", Status: printer.CAInjectionMismatch, Ready: true},
+			want:          []printer.Finding{finding(printer.SeverityCritical, "CABundle does not match the CA of secret webhooks/webhook-tls")},
+		},
+		{
+			name:          "mismatch failing open",
+			failurePolicy: "Ignore",
+			injection:     &printer.PrintCAInjectionItem{Secret: "webhooks/webhook-tls", Status: printer.CAInjectionMismatch},
+			want:          []printer.Finding{finding(printer.SeverityWarning, "CABundle does not match the CA of secret webhooks/webhook-tls")},
+		},
+		{
+			name:          "certificate missing",
+			failurePolicy: "Fail",
+			injection:     &printer.PrintCAInjectionItem{Certificate: "webhooks/webhook", Status: printer.CAInjectionCertificateMissing},
+			want:          []printer.Finding{finding(printer.SeverityCritical, "certificate webhooks/webhook the CABundle is injected from does not exist")},
+		},
+		{
+			name:          "secret missing",
+			failurePolicy: "Fail",
+			injection:     &printer.PrintCAInjectionItem{Certificate: "webhooks/webhook", Secret: "webhooks/webhook-tls", Status: printer.CAInjectionSecretMissing, Ready: true},
+			want:          []printer.Finding{finding(printer.SeverityCritical, "secret webhooks/webhook-tls the CABundle is injected from does not exist")},
+		},
+		{
+			name:          "error failing closed",
+			failurePolicy: "Fail",
+			injection:     &printer.PrintCAInjectionItem{Secret: "webhooks/webhook-tls", Status: printer.CAInjectionError, Error: "forbidden"},
+			want:          []printer.Finding{finding(printer.SeverityWarning, "CA injection could not be verified: forbidden")},
+		},
+		{
+			name:      "renewing",
+			injection: &printer.PrintCAInjectionItem{Certificate: "webhooks/webhook", Status: printer.CAInjectionInSync, Ready: true, Renewing: true},
+			want:      []printer.Finding{finding(printer.SeverityWarning, "certificate webhooks/webhook is pending renewal, the CABundle may change")},
+		},
+		{
+			name:      "not ready",
+			injection: &printer.PrintCAInjectionItem{Certificate: "webhooks/webhook", Status: printer.CAInjectionInSync},
+			want:      []printer.Finding{finding(printer.SeverityWarning, "certificate webhooks/webhook is not ready")},
+		},
+	}
+
+	check := &CAInjection{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := check.Check(printer.PrintItem{FailurePolicy: tt.failurePolicy, CAInjection: tt.injection})
+			if !reflect.DeepEqual(findings, tt.want) {
+				t.Errorf("got %+v, want %+v", findings, tt.want)
+			}
+		})
+	}
+}
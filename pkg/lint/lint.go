@@ -0,0 +1,168 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"strings"
+)
+
+// Check inspects a single webhook and reports the problems it found.
+type Check interface {
+	Name() string
+	Check(item printer.PrintItem) []printer.Finding
+}
+
+// Linter runs a set of checks over every webhook of a PrintModel.
+type Linter struct {
+	checks []Check
+}
+
+// NewLinter constructs a new Linter with the specified checks
+func NewLinter(checks ...Check) *Linter {
+	return &Linter{
+		checks: checks,
+	}
+}
+
+// Config holds the settings of the checks.
+type Config struct {
+	// SensitiveResources are the resources, in "resource.group" notation,
+	// the sensitive-resources check reports.
+	SensitiveResources []string
+	// SystemNamespaces are the namespaces the sensitive-resources check reports.
+	SystemNamespaces []string
+	// Disabled are the names of the checks that are not run.
+	Disabled []string
+}
+
+// NewConfig provides an instance of Config with default values
+func NewConfig() *Config {
+	return &Config{
+		SensitiveResources: DefaultSensitiveResources,
+		SystemNamespaces:   DefaultSystemNamespaces,
+	}
+}
+
+// Checks returns every check configured with the Config.
+func (c *Config) Checks() []Check {
+	sensitive := &SensitiveResources{
+		Namespaces: c.SystemNamespaces,
+	}
+	for _, r := range c.SensitiveResources {
+		sensitive.Resources = append(sensitive.Resources, ParseGroupResource(r))
+	}
+
+	var checks []Check
+	for _, check := range []Check{
+		&SelfInterception{},
+		sensitive,
+		&CAInjection{},
+		&ServingCert{},
+		&MatchConditions{},
+		&UnservedResources{},
+	} {
+		if !containsString(c.Disabled, check.Name()) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// Validate ensures that every disabled check exists.
+func (c *Config) Validate() error {
+	var names []string
+	for _, check := range NewConfig().Checks() {
+		names = append(names, check.Name())
+	}
+	for _, d := range c.Disabled {
+		if !containsString(names, d) {
+			return fmt.Errorf("unknown check %q, must be one of %v", d, names)
+		}
+	}
+	return nil
+}
+
+// Run attaches the findings of every check to the items of the given model.
+func (l *Linter) Run(model *printer.PrintModel) {
+	for i := range model.Items {
+		for _, c := range l.checks {
+			model.Items[i].Findings = append(model.Items[i].Findings, c.Check(model.Items[i])...)
+		}
+	}
+}
+
+// GroupResource is an API resource a rule may intercept.
+type GroupResource struct {
+	Group    string
+	Resource string
+}
+
+// ParseGroupResource parses the kubectl style "resource.group" notation,
+// e.g. "leases.coordination.k8s.io", or just "nodes" for the core group.
+func ParseGroupResource(s string) GroupResource {
+	parts := strings.SplitN(s, ".", 2)
+	if len(parts) == 1 {
+		return GroupResource{Resource: parts[0]}
+	}
+	return GroupResource{Resource: parts[0], Group: parts[1]}
+}
+
+func (gr GroupResource) String() string {
+	if gr.Group == "" {
+		return gr.Resource
+	}
+	return gr.Resource + "." + gr.Group
+}
+
+// interceptsResource reports whether the given rule matches the
+// given resource, honouring "*" in groups and resources.
+func interceptsResource(rm printer.ResourceModel, gr GroupResource) bool {
+	groupMatched := len(rm.APIGroups) == 0
+	for _, g := range rm.APIGroups {
+		if g == "*" || g == gr.Group {
+			groupMatched = true
+		}
+	}
+	if !groupMatched {
+		return false
+	}
+
+	for _, r := range rm.Resources {
+		if r == "*" || r == "*/*" || r == gr.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// interceptsOperation reports whether the given rule matches
+// any of the given operations.
+func interceptsOperation(rm printer.ResourceModel, ops ...string) bool {
+	for _, op := range rm.Operations {
+		if op == "*" {
+			return true
+		}
+		for _, o := range ops {
+			if strings.EqualFold(op, o) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"strings"
+	"testing"
+)
+
+func TestConfigChecks(t *testing.T) {
+	c := NewConfig()
+	c.Disabled = []string{"self-interception"}
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, check := range c.Checks() {
+		if check.Name() == "self-interception" {
+			t.Error("disabled check self-interception is still run")
+		}
+	}
+
+	c.Disabled = []string{"no-such-check"}
+	if err := c.Validate(); err == nil || !strings.Contains(err.Error(), `unknown check "no-such-check"`) {
+		t.Errorf("error = %v, want an unknown check", err)
+	}
+}
+
+func TestLinterRun(t *testing.T) {
+	item := podWebhook()
+	item.ActiveNamespaces = append(item.ActiveNamespaces, "kube-system")
+	model := &printer.PrintModel{Items: []printer.PrintItem{item, {Name: "empty"}}}
+
+	NewLinter(NewConfig().Checks()...).Run(model)
+
+	var checks []string
+	for _, f := range model.Items[0].Findings {
+		checks = append(checks, f.Check)
+	}
+	if got := strings.Join(checks, ","); got != "self-interception,sensitive-resources" {
+		t.Errorf("findings of the first item are from %s, want self-interception,sensitive-resources", got)
+	}
+	if len(model.Items[1].Findings) != 0 {
+		t.Errorf("second item has findings %+v, want none", model.Items[1].Findings)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+)
+
+// MatchConditions flags matchConditions that do not compile. The API
+// server rejects such configurations on write, so they usually point at
+// an expression relying on a CEL library this plugin does not know.
+type MatchConditions struct{}
+
+func (c *MatchConditions) Name() string {
+	return "match-conditions"
+}
+
+func (c *MatchConditions) Check(item printer.PrintItem) []printer.Finding {
+	var findings []printer.Finding
+	for _, mc := range item.MatchConditions {
+		if mc.Error == "" {
+			continue
+		}
+		findings = append(findings, printer.Finding{
+			Check:    c.Name(),
+			Severity: printer.SeverityWarning,
+			Message:  fmt.Sprintf("matchCondition %q does not compile: %s", mc.Name, mc.Error),
+		})
+	}
+	return findings
+}
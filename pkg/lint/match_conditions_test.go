@@ -0,0 +1,39 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"reflect"
+	"testing"
+)
+
+func TestMatchConditions(t *testing.T) {
+	item := printer.PrintItem{FailurePolicy: "Fail", MatchConditions: []printer.PrintMatchConditionItem{
+		{Name: "create", Expression: `request.operation == "CREATE"`},
+		{Name: "broken", Expression: `request.operation ==`, Error: "Syntax error: mismatched input"},
+	}}
+
+	want := []printer.Finding{{
+		Check:    "match-conditions",
+		Severity: printer.SeverityWarning,
+		Message:  `matchCondition "broken" does not compile: Syntax error: mismatched input`,
+	}}
+	if findings := (&MatchConditions{}).Check(item); !reflect.DeepEqual(findings, want) {
+		t.Errorf("got %+v, want %+v", findings, want)
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"strings"
+)
+
+// workloadResources are the resources a webhook's own pods are
+// (re)created through.
+var workloadResources = []GroupResource{
+	{Group: "", Resource: "pods"},
+	{Group: "apps", Resource: "deployments"},
+	{Group: "apps", Resource: "replicasets"},
+}
+
+// SelfInterception flags webhooks that fail closed on the pods of their
+// own backing service. Once those pods are gone the webhook rejects their
+// replacements and blocks its own recovery.
+type SelfInterception struct{}
+
+func (c *SelfInterception) Name() string {
+	return "self-interception"
+}
+
+func (c *SelfInterception) Check(item printer.PrintItem) []printer.Finding {
+	service := item.Webhook.Service
+	if item.FailurePolicy != "Fail" || service.Name == "" {
+		return nil
+	}
+
+	if !containsString(item.ActiveNamespaces, service.Namespace) {
+		return nil
+	}
+
+	// the service's selector is the best knowledge we have about the labels
+	// of its pods, an objectSelector that rejects them is an escape hatch
+	if !printer.SelectsEverything(item.ObjectSelector) {
+		selector, err := metaV1.LabelSelectorAsSelector(item.ObjectSelector)
+		if err != nil || !selector.Matches(labels.Set(service.Selector)) {
+			return nil
+		}
+	}
+
+	var intercepted []string
+	for _, gr := range workloadResources {
+		for _, rm := range item.ResourceModels {
+			if interceptsResource(rm, gr) && interceptsOperation(rm, "CREATE", "UPDATE") {
+				intercepted = append(intercepted, gr.Resource)
+				break
+			}
+		}
+	}
+	if len(intercepted) == 0 {
+		return nil
+	}
+
+	return []printer.Finding{{
+		Check:    c.Name(),
+		Severity: printer.SeverityCritical,
+		Message: fmt.Sprintf("blocks its own recovery, fails closed on %s in its own namespace %q",
+			strings.Join(intercepted, ", "), service.Namespace),
+	}}
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
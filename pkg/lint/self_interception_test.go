@@ -0,0 +1,125 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+// podWebhook returns a webhook failing closed on pod creation, served by
+// pods labelled app=webhook in the webhooks namespace it is active in.
+func podWebhook() printer.PrintItem {
+	return printer.PrintItem{
+		Name:          "pod-policy",
+		Kind:          "Validating",
+		FailurePolicy: "Fail",
+		Webhook: printer.PrintWebhookItem{
+			Name: "pods.policy.io",
+			Service: printer.PrintServiceItem{
+				Name:      "webhook",
+				Namespace: "webhooks",
+				Selector:  map[string]string{"app": "webhook"},
+			},
+		},
+		ResourceModels: []printer.ResourceModel{{
+			APIGroups:  []string{""},
+			Operations: []string{"CREATE"},
+			Resources:  []string{"pods"},
+		}},
+		ActiveNamespaces: []string{"default", "webhooks"},
+	}
+}
+
+func TestSelfInterception(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(item *printer.PrintItem)
+		message string
+	}{
+		{
+			name:    "fails closed on its own pods",
+			modify:  func(item *printer.PrintItem) {},
+			message: `blocks its own recovery, fails closed on pods in its own namespace "webhooks"`,
+		},
+		{
+			name: "fails closed on every workload",
+			modify: func(item *printer.PrintItem) {
+				item.ResourceModels = []printer.ResourceModel{{
+					APIGroups:  []string{"*"},
+					Operations: []string{"*"},
+					Resources:  []string{"*"},
+				}}
+			},
+			message: `blocks its own recovery, fails closed on pods, deployments, replicasets in its own namespace "webhooks"`,
+		},
+		{
+			name:   "fails open",
+			modify: func(item *printer.PrintItem) { item.FailurePolicy = "Ignore" },
+		},
+		{
+			name:   "own namespace not selected",
+			modify: func(item *printer.PrintItem) { item.ActiveNamespaces = []string{"default"} },
+		},
+		{
+			name:   "url webhook",
+			modify: func(item *printer.PrintItem) { item.Webhook.Service = printer.PrintServiceItem{} },
+		},
+		{
+			name: "delete only",
+			modify: func(item *printer.PrintItem) {
+				item.ResourceModels[0].Operations = []string{"DELETE"}
+			},
+		},
+		{
+			name: "objectSelector excludes its pods",
+			modify: func(item *printer.PrintItem) {
+				item.ObjectSelector = &metaV1.LabelSelector{MatchLabels: map[string]string{"app": "api"}}
+			},
+		},
+		{
+			name: "objectSelector selects its pods",
+			modify: func(item *printer.PrintItem) {
+				item.ObjectSelector = &metaV1.LabelSelector{MatchLabels: map[string]string{"app": "webhook"}}
+			},
+			message: `blocks its own recovery, fails closed on pods in its own namespace "webhooks"`,
+		},
+	}
+
+	check := &SelfInterception{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := podWebhook()
+			tt.modify(&item)
+			findings := check.Check(item)
+
+			if tt.message == "" {
+				if len(findings) != 0 {
+					t.Errorf("got findings %+v, want none", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("got findings %+v, want one", findings)
+			}
+			if findings[0].Message != tt.message || findings[0].Severity != printer.SeverityCritical {
+				t.Errorf("got %s %q, want critical %q", findings[0].Severity, findings[0].Message, tt.message)
+			}
+		})
+	}
+}
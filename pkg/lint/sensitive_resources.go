@@ -0,0 +1,103 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"strings"
+)
+
+// DefaultSensitiveResources are resources the control plane depends on.
+// Kubernetes exempts some of them from admission webhooks only partially.
+var DefaultSensitiveResources = []string{
+	"nodes",
+	"events",
+	"events.events.k8s.io",
+	"leases.coordination.k8s.io",
+	"tokenreviews.authentication.k8s.io",
+	"subjectaccessreviews.authorization.k8s.io",
+	"mutatingwebhookconfigurations.admissionregistration.k8s.io",
+	"validatingwebhookconfigurations.admissionregistration.k8s.io",
+}
+
+// DefaultSystemNamespaces are the namespaces the control plane runs in.
+var DefaultSystemNamespaces = []string{
+	"kube-system",
+	"kube-node-lease",
+}
+
+// SensitiveResources flags webhooks intercepting resources or namespaces
+// the control plane depends on. A failing webhook on them easily turns
+// into a cluster outage.
+type SensitiveResources struct {
+	Resources  []GroupResource
+	Namespaces []string
+}
+
+func (c *SensitiveResources) Name() string {
+	return "sensitive-resources"
+}
+
+func (c *SensitiveResources) Check(item printer.PrintItem) []printer.Finding {
+	severity := printer.SeverityWarning
+	if item.FailurePolicy == "Fail" {
+		severity = printer.SeverityCritical
+	}
+
+	var findings []printer.Finding
+
+	var intercepted []string
+	for _, gr := range c.Resources {
+		for _, rm := range item.ResourceModels {
+			if interceptsResource(rm, gr) {
+				intercepted = append(intercepted, gr.String())
+				break
+			}
+		}
+	}
+	if len(intercepted) > 0 {
+		findings = append(findings, printer.Finding{
+			Check:    c.Name(),
+			Severity: severity,
+			Message:  fmt.Sprintf("intercepts control plane resources %s", strings.Join(intercepted, ", ")),
+		})
+	}
+
+	namespaced := false
+	for _, rm := range item.ResourceModels {
+		if rm.Scope != "Cluster" {
+			namespaced = true
+		}
+	}
+
+	var namespaces []string
+	for _, ns := range c.Namespaces {
+		if namespaced && containsString(item.ActiveNamespaces, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	if len(namespaces) > 0 {
+		findings = append(findings, printer.Finding{
+			Check:    c.Name(),
+			Severity: severity,
+			Message:  fmt.Sprintf("intercepts requests in system namespaces %s", strings.Join(namespaces, ", ")),
+		})
+	}
+
+	return findings
+}
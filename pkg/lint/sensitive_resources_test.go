@@ -0,0 +1,100 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"reflect"
+	"testing"
+)
+
+func TestSensitiveResources(t *testing.T) {
+	tests := []struct {
+		name          string
+		failurePolicy string
+		rules         []printer.ResourceModel
+		namespaces    []string
+		want          []printer.Finding
+	}{
+		{
+			name:          "nodes failing closed",
+			failurePolicy: "Fail",
+			rules:         []printer.ResourceModel{{APIGroups: []string{""}, Resources: []string{"nodes"}, Scope: "Cluster"}},
+			want: []printer.Finding{
+				{Check: "sensitive-resources", Severity: printer.SeverityCritical, Message: "intercepts control plane resources nodes"},
+			},
+		},
+		{
+			name:          "leases failing open",
+			failurePolicy: "Ignore",
+			rules:         []printer.ResourceModel{{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Scope: "Namespaced"}},
+			want: []printer.Finding{
+				{Check: "sensitive-resources", Severity: printer.SeverityWarning, Message: "intercepts control plane resources leases.coordination.k8s.io"},
+			},
+		},
+		{
+			name:          "leases of another group",
+			failurePolicy: "Fail",
+			rules:         []printer.ResourceModel{{APIGroups: []string{"example.io"}, Resources: []string{"leases"}, Scope: "Namespaced"}},
+		},
+		{
+			name:          "everything in kube-system",
+			failurePolicy: "Fail",
+			rules:         []printer.ResourceModel{{APIGroups: []string{"*"}, Resources: []string{"*"}, Scope: "*"}},
+			namespaces:    []string{"default", "kube-system"},
+			want: []printer.Finding{
+				{Check: "sensitive-resources", Severity: printer.SeverityCritical, Message: "intercepts control plane resources nodes, leases.coordination.k8s.io"},
+				{Check: "sensitive-resources", Severity: printer.SeverityCritical, Message: "intercepts requests in system namespaces kube-system"},
+			},
+		},
+		{
+			name:          "cluster scoped rules in kube-system",
+			failurePolicy: "Fail",
+			rules:         []printer.ResourceModel{{APIGroups: []string{"rbac.authorization.k8s.io"}, Resources: []string{"clusterroles"}, Scope: "Cluster"}},
+			namespaces:    []string{"kube-system"},
+		},
+	}
+
+	check := &SensitiveResources{
+		Resources:  []GroupResource{ParseGroupResource("nodes"), ParseGroupResource("leases.coordination.k8s.io")},
+		Namespaces: []string{"kube-system", "kube-node-lease"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := check.Check(printer.PrintItem{
+				FailurePolicy:    tt.failurePolicy,
+				ResourceModels:   tt.rules,
+				ActiveNamespaces: tt.namespaces,
+			})
+			if !reflect.DeepEqual(findings, tt.want) {
+				t.Errorf("got %+v, want %+v", findings, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGroupResource(t *testing.T) {
+	for s, want := range map[string]GroupResource{
+		"nodes":                      {Resource: "nodes"},
+		"leases.coordination.k8s.io": {Group: "coordination.k8s.io", Resource: "leases"},
+	} {
+		got := ParseGroupResource(s)
+		if got != want || got.String() != s {
+			t.Errorf("ParseGroupResource(%q) = %+v (%s), want %+v", s, got, got, want)
+		}
+	}
+}
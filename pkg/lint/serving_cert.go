@@ -0,0 +1,57 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"strings"
+)
+
+// ServingCert flags webhooks none of whose pods' TLS secrets is trusted by
+// the CABundle for the service's DNS name. The webhook keeps working until
+// its pods reload the secret, after which every call fails verification.
+type ServingCert struct{}
+
+func (c *ServingCert) Name() string {
+	return "serving-cert"
+}
+
+func (c *ServingCert) Check(item printer.PrintItem) []printer.Finding {
+	secrets := item.Webhook.Service.ServingSecrets
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	var errs []string
+	for _, s := range secrets {
+		if s.Verified {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", s.Name, s.VerifyError))
+	}
+
+	severity := printer.SeverityWarning
+	if item.FailurePolicy == "Fail" {
+		severity = printer.SeverityCritical
+	}
+	return []printer.Finding{{
+		Check:    c.Name(),
+		Severity: severity,
+		Message:  fmt.Sprintf("CA bundle and serving cert diverged, %s", strings.Join(errs, "; ")),
+	}}
+}
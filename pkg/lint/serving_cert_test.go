@@ -0,0 +1,74 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"reflect"
+	"testing"
+)
+
+func TestServingCert(t *testing.T) {
+	trusted := printer.PrintServingSecretItem{Name: "webhook-tls", Verified: true}
+	stale := printer.PrintServingSecretItem{Name: "old-tls", VerifyError: "x509: certificate signed by unknown authority"}
+
+	tests := []struct {
+		name          string
+		failurePolicy string
+		secrets       []printer.PrintServingSecretItem
+		want          []printer.Finding
+	}{
+		{
+			name: "no secrets",
+		},
+		{
+			name:    "one trusted secret",
+			secrets: []printer.PrintServingSecretItem{stale, trusted},
+		},
+		{
+			name:          "diverged failing closed",
+			failurePolicy: "Fail",
+			secrets:       []printer.PrintServingSecretItem{stale},
+			want: []printer.Finding{{
+				Check:    "serving-cert",
+				Severity: printer.SeverityCritical,
+				Message:  "CA bundle and serving cert diverged, old-tls: x509: certificate signed by unknown authority",
+			}},
+		},
+		{
+			name:          "diverged failing open",
+			failurePolicy: "Ignore",
+			secrets:       []printer.PrintServingSecretItem{stale},
+			want: []printer.Finding{{
+				Check:    "serving-cert",
+				Severity: printer.SeverityWarning,
+				Message:  "CA bundle and serving cert diverged, old-tls: x509: certificate signed by unknown authority",
+			}},
+		},
+	}
+
+	check := &ServingCert{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := printer.PrintItem{FailurePolicy: tt.failurePolicy}
+			item.Webhook.Service.ServingSecrets = tt.secrets
+			if findings := check.Check(item); !reflect.DeepEqual(findings, tt.want) {
+				t.Errorf("got %+v, want %+v", findings, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+)
+
+// UnservedResources flags rules targeting resources or versions the
+// cluster does not serve, typically a removed beta version, which make
+// the webhook silently never fire for them. Items of clusters that could
+// not be discovered have no unserved resources.
+type UnservedResources struct{}
+
+func (c *UnservedResources) Name() string {
+	return "unserved-resources"
+}
+
+func (c *UnservedResources) Check(item printer.PrintItem) []printer.Finding {
+	var findings []printer.Finding
+	for _, rm := range item.ResourceModels {
+		for _, r := range rm.Unserved {
+			findings = append(findings, printer.Finding{
+				Check:    c.Name(),
+				Severity: printer.SeverityWarning,
+				Message:  fmt.Sprintf("rule targets %s, which the cluster does not serve", r),
+			})
+		}
+	}
+	return findings
+}
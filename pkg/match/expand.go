@@ -0,0 +1,65 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+)
+
+// Expand sets the served resources matched by every wildcard rule of the
+// given model, so the blast radius of a "*" can be told.
+func Expand(model *printer.PrintModel, resources []printer.APIResource) {
+	for i := range model.Items {
+		for j := range model.Items[i].ResourceModels {
+			rm := &model.Items[i].ResourceModels[j]
+			if !hasWildcard(rm) {
+				continue
+			}
+			rm.Expanded = []string{}
+			for _, r := range resources {
+				if resourceInRule(*rm, r) {
+					gvr := schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+					rm.Expanded = append(rm.Expanded, resourceString(gvr))
+				}
+			}
+		}
+	}
+}
+
+// resourceInRule tells whether the rule selects the resource, whatever
+// the operation.
+func resourceInRule(rm printer.ResourceModel, r printer.APIResource) bool {
+	if !matchesAny(rm.APIGroups, r.Group) ||
+		!matchesAny(rm.APIVersions, r.Version) ||
+		!resourceMatches(rm.Resources, r.Resource) {
+		return false
+	}
+	return !(rm.Scope == "Cluster" && r.Namespaced) && !(rm.Scope == "Namespaced" && !r.Namespaced)
+}
+
+func hasWildcard(rm *printer.ResourceModel) bool {
+	for _, values := range [][]string{rm.APIGroups, rm.APIVersions, rm.Resources} {
+		for _, v := range values {
+			if strings.Contains(v, "*") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match_test
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	resources := []printer.APIResource{
+		{Version: "v1", Resource: "namespaces"},
+		{Version: "v1", Resource: "pods", Namespaced: true},
+		{Version: "v1", Resource: "pods/exec", Namespaced: true},
+		{Group: "apps", Version: "v1", Resource: "deployments", Namespaced: true},
+		{Group: "apps", Version: "v1", Resource: "deployments/scale", Namespaced: true},
+	}
+
+	tests := []struct {
+		name string
+		rule printer.ResourceModel
+		want []string
+	}{
+		{
+			name: "no wildcard",
+			rule: printer.ResourceModel{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}},
+		},
+		{
+			name: "all resources of a group",
+			rule: printer.ResourceModel{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"*"}},
+			want: []string{"v1/namespaces", "v1/pods"},
+		},
+		{
+			name: "namespaced subresources",
+			rule: printer.ResourceModel{APIGroups: []string{"*"}, APIVersions: []string{"*"}, Resources: []string{"*/*"}, Scope: "Namespaced"},
+			want: []string{"v1/pods", "v1/pods/exec", "apps/v1/deployments", "apps/v1/deployments/scale"},
+		},
+		{
+			name: "nothing served",
+			rule: printer.ResourceModel{APIGroups: []string{"batch"}, APIVersions: []string{"*"}, Resources: []string{"*"}},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &printer.PrintModel{Items: []printer.PrintItem{{ResourceModels: []printer.ResourceModel{tt.rule}}}}
+			match.Expand(model, resources)
+			if got := model.Items[0].ResourceModels[0].Expanded; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package match resolves which webhooks and policies of a PrintModel
+// apply to an admission request for a given object.
+package match
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/matchcondition"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	authenticationV1 "k8s.io/api/authentication/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+)
+
+// Request is an admission request as far as matching is concerned.
+type Request struct {
+	Operation string
+	Resource  schema.GroupVersionResource
+	Kind      schema.GroupVersionKind
+	// Namespaced tells whether the resource is namespaced.
+	Namespaced bool
+	Object     *unstructured.Unstructured
+	OldObject  *unstructured.Unstructured
+	// NamespaceObject is the namespace of a namespaced object.
+	NamespaceObject *unstructured.Unstructured
+	// UserInfo is the user making the request, the API server's
+	// authorizer is not available to check what it is allowed to do.
+	UserInfo authenticationV1.UserInfo
+	// Equivalents are the other served versions of the resource, which
+	// the API server converts the request to for the webhooks and
+	// policies with matchPolicy Equivalent.
+	Equivalents []schema.GroupVersionResource
+}
+
+// Result tells whether a webhook applies to a Request and why. Unknown is
+// set when it cannot be told, as a matchCondition cannot be evaluated.
+type Result struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+	Webhook string `json:"webhook"`
+	Matched bool   `json:"matched"`
+	Unknown bool   `json:"unknown,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// Match resolves every item of the given model against the request.
+func Match(model *printer.PrintModel, req Request) []Result {
+	var results []Result
+	for _, item := range model.Items {
+		matched, reason, err := Item(item, req)
+		if err != nil {
+			reason = err.Error()
+		}
+		results = append(results, Result{
+			Kind:    item.Kind,
+			Name:    item.Name,
+			Webhook: item.Webhook.Name,
+			Matched: matched,
+			Unknown: err != nil,
+			Reason:  reason,
+		})
+	}
+	return results
+}
+
+// Item resolves whether the given webhook applies to the request, checking
+// rules, namespaces, objectSelector and matchConditions in the order the
+// API server does. An error is returned when a matchCondition cannot be
+// evaluated, typically as it needs the authorizer or a field of the
+// request that is not known here, rather than guessing what the API
+// server would make of it.
+func Item(item printer.PrintItem, req Request) (bool, string, error) {
+	resource, ok := req.interceptedResource(item.MatchPolicy, item.ResourceModels)
+	if !ok {
+		return false, "no rule matches " + req.Operation + " " + resourceString(req.Resource), nil
+	}
+	if rulesMatch(item.ExcludedResourceModels, req, resource) {
+		return false, req.Operation + " " + resourceString(resource) + " is excluded", nil
+	}
+
+	if req.Namespaced {
+		if selected, reason := req.namespaceSelectedBy(item.NamespaceSelector, item.ActiveNamespaces); !selected {
+			return false, reason, nil
+		}
+	}
+
+	if selected, err := req.selectedBy(item.ObjectSelector); err != nil {
+		return false, fmt.Sprintf("invalid objectSelector: %v", err), nil
+	} else if !selected {
+		return false, "objectSelector does not select the object", nil
+	}
+
+	if item.Policy != nil {
+		if matched, reason := bindingsMatch(item.Policy.Bindings, req, item.MatchPolicy); !matched {
+			return false, reason, nil
+		}
+	}
+
+	vars := req.variables()
+	for _, mc := range item.MatchConditions {
+		ok, err := matchcondition.Evaluate(mc.Expression, vars)
+		if err != nil {
+			// the API server rejects the request when failing closed, and
+			// skips the webhook otherwise, if it cannot evaluate the
+			// condition either
+			return false, "", fmt.Errorf("cannot evaluate matchCondition %q (failurePolicy %s): %v", mc.Name, item.FailurePolicy, err)
+		}
+		if !ok {
+			return false, fmt.Sprintf("matchCondition %q is false", mc.Name), nil
+		}
+	}
+
+	reason := "rules and selectors match"
+	if len(item.MatchConditions) > 0 {
+		reason = "rules, selectors and matchConditions match"
+	}
+	if resource != req.Resource {
+		reason += " as equivalent " + resourceString(resource)
+	}
+	return true, reason, nil
+}
+
+// bindingsMatch tells whether any of the bindings of a policy applies it
+// to the request, as the policy's constraints only apply through them.
+func bindingsMatch(bindings []printer.PrintPolicyBindingItem, req Request, matchPolicy string) (bool, string) {
+	if len(bindings) == 0 {
+		return false, "no binding applies the policy"
+	}
+
+	var reason string
+	for _, b := range bindings {
+		selected, err := req.selectedBy(b.ObjectSelector)
+		resource, ok := req.interceptedResource(matchPolicy, b.ResourceModels)
+		switch {
+		case !ok || rulesMatch(b.ExcludedResourceModels, req, resource):
+			reason = fmt.Sprintf("binding %q does not match %s %s", b.Name, req.Operation, resourceString(req.Resource))
+		case req.Namespaced && !bindingSelectsNamespace(b, req):
+			_, why := req.namespaceSelectedBy(b.NamespaceSelector, b.ActiveNamespaces)
+			reason = fmt.Sprintf("binding %q: %s", b.Name, why)
+		case err != nil:
+			reason = fmt.Sprintf("binding %q has an invalid objectSelector: %v", b.Name, err)
+		case !selected:
+			reason = fmt.Sprintf("binding %q does not select the object", b.Name)
+		default:
+			return true, ""
+		}
+	}
+	if len(bindings) > 1 {
+		return false, fmt.Sprintf("none of the %d bindings applies the policy", len(bindings))
+	}
+	return false, reason
+}
+
+// bindingSelectsNamespace tells whether the binding applies the policy in
+// the namespace of the request.
+func bindingSelectsNamespace(b printer.PrintPolicyBindingItem, req Request) bool {
+	selected, _ := req.namespaceSelectedBy(b.NamespaceSelector, b.ActiveNamespaces)
+	return selected
+}
+
+// interceptedResource returns the resource of the request the given rules
+// intercept, with matchPolicy Equivalent the first of its equivalents they
+// do when they do not intercept the resource itself.
+func (r Request) interceptedResource(matchPolicy string, rules []printer.ResourceModel) (schema.GroupVersionResource, bool) {
+	candidates := []schema.GroupVersionResource{r.Resource}
+	if matchPolicy == "Equivalent" {
+		candidates = append(candidates, r.Equivalents...)
+	}
+	for _, candidate := range candidates {
+		if rulesMatch(rules, r, candidate) {
+			return candidate, true
+		}
+	}
+	return schema.GroupVersionResource{}, false
+}
+
+// rulesMatch tells whether any of the rules matches the request for the
+// given resource.
+func rulesMatch(rules []printer.ResourceModel, req Request, resource schema.GroupVersionResource) bool {
+	for _, rm := range rules {
+		if !RuleMatches(rm, req.Operation, resource, req.Namespaced) {
+			continue
+		}
+		if len(rm.ResourceNames) > 0 && (req.Object == nil || !containsString(rm.ResourceNames, req.Object.GetName())) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func matchesAny(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceMatches honours "*" for all resources, "*/*" for all resources
+// and subresources and "*/sub" or "res/*" wildcards.
+func resourceMatches(resources []string, resource string) bool {
+	for _, r := range resources {
+		if r == "*/*" || r == resource {
+			return true
+		}
+		if r == "*" && !strings.Contains(resource, "/") {
+			return true
+		}
+		parts, want := strings.SplitN(r, "/", 2), strings.SplitN(resource, "/", 2)
+		if len(parts) == 2 && len(want) == 2 &&
+			(parts[0] == "*" || parts[0] == want[0]) && (parts[1] == "*" || parts[1] == want[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Request) namespace() string {
+	if r.Object != nil && r.Object.GetNamespace() != "" {
+		return r.Object.GetNamespace()
+	}
+	if r.OldObject != nil {
+		return r.OldObject.GetNamespace()
+	}
+	return ""
+}
+
+// selectedBy applies the given objectSelector to the object or, as the
+// API server does, to the old object when the new one does not match. A
+// nil selector selects every object.
+func (r Request) selectedBy(objectSelector *metaV1.LabelSelector) (bool, error) {
+	if objectSelector == nil {
+		return true, nil
+	}
+	selector, err := metaV1.LabelSelectorAsSelector(objectSelector)
+	if err != nil {
+		return false, err
+	}
+	for _, o := range []*unstructured.Unstructured{r.Object, r.OldObject} {
+		if o != nil && selector.Matches(labels.Set(o.GetLabels())) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// namespaceSelectedBy tells whether the given namespaceSelector selects
+// the namespace of the request, and why not. It is applied to the labels
+// of the NamespaceObject when given, as the API server does. Otherwise the
+// namespace is looked up in the given active namespaces, which are only
+// known when the namespaces could be listed, so a namespace missing from
+// them is reported as unknown unless the selector selects every namespace.
+func (r Request) namespaceSelectedBy(namespaceSelector *metaV1.LabelSelector, active []string) (bool, string) {
+	namespace := r.namespace()
+	selector := labels.Everything()
+	if namespaceSelector != nil {
+		s, err := metaV1.LabelSelectorAsSelector(namespaceSelector)
+		if err != nil {
+			return false, fmt.Sprintf("invalid namespaceSelector: %v", err)
+		}
+		selector = s
+	}
+
+	switch {
+	case r.NamespaceObject != nil && selector.Matches(labels.Set(r.NamespaceObject.GetLabels())):
+		return true, ""
+	case r.NamespaceObject != nil:
+		return false, fmt.Sprintf("namespace %q is not selected", namespace)
+	case selector.Empty() || containsString(active, namespace):
+		return true, ""
+	}
+	return false, fmt.Sprintf("namespace %q is unknown, its labels are needed to evaluate the namespaceSelector", namespace)
+}
+
+// variables returns the CEL variables of the request.
+func (r Request) variables() map[string]interface{} {
+	vars := map[string]interface{}{
+		"request": map[string]interface{}{
+			"operation": r.Operation,
+			"namespace": r.namespace(),
+			"kind":      map[string]interface{}{"group": r.Kind.Group, "version": r.Kind.Version, "kind": r.Kind.Kind},
+			"resource":  map[string]interface{}{"group": r.Resource.Group, "version": r.Resource.Version, "resource": r.Resource.Resource},
+			"userInfo":  r.userInfo(),
+		},
+	}
+	request := vars["request"].(map[string]interface{})
+	request["requestKind"], request["requestResource"] = request["kind"], request["resource"]
+	if r.Object != nil {
+		vars["object"] = r.Object.Object
+		request["name"] = r.Object.GetName()
+	} else if r.OldObject != nil {
+		request["name"] = r.OldObject.GetName()
+	}
+	if r.OldObject != nil {
+		vars["oldObject"] = r.OldObject.Object
+	}
+	if r.NamespaceObject != nil {
+		vars["namespaceObject"] = r.NamespaceObject.Object
+	}
+	return vars
+}
+
+func resourceString(gvr schema.GroupVersionResource) string {
+	if gvr.Group == "" {
+		return gvr.Version + "/" + gvr.Resource
+	}
+	return gvr.Group + "/" + gvr.Version + "/" + gvr.Resource
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// userInfo returns the user of the request the way the API server passes
+// it to CEL, leaving out the fields that are not set.
+func (r Request) userInfo() map[string]interface{} {
+	userInfo := map[string]interface{}{}
+	if r.UserInfo.Username != "" {
+		userInfo["username"] = r.UserInfo.Username
+	}
+	if r.UserInfo.UID != "" {
+		userInfo["uid"] = r.UserInfo.UID
+	}
+	if len(r.UserInfo.Groups) > 0 {
+		var groups []interface{}
+		for _, g := range r.UserInfo.Groups {
+			groups = append(groups, g)
+		}
+		userInfo["groups"] = groups
+	}
+	return userInfo
+}
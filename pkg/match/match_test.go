@@ -0,0 +1,438 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match_test
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/k8s"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	admissionV1 "k8s.io/api/admissionregistration/v1"
+	authenticationV1 "k8s.io/api/authentication/v1"
+	coreV1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	"strings"
+	"testing"
+)
+
+// webhookItem builds the item of a webhook intercepting configmaps in the
+// namespaces labelled team=payments, unless their skip label is set.
+func webhookItem(t *testing.T, failurePolicy admissionV1.FailurePolicyType, conditions ...admissionV1.MatchCondition) printer.PrintItem {
+	t.Helper()
+	url := "https://webhook.example.com/validate"
+	configuration := admissionV1.ValidatingWebhookConfiguration{
+		ObjectMeta: metaV1.ObjectMeta{Name: "configmaps"},
+		Webhooks: []admissionV1.ValidatingWebhook{{
+			Name:              "configmaps.example.com",
+			ClientConfig:      admissionV1.WebhookClientConfig{URL: &url},
+			FailurePolicy:     &failurePolicy,
+			NamespaceSelector: &metaV1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+			MatchConditions:   conditions,
+			Rules: []admissionV1.RuleWithOperations{{
+				Operations: []admissionV1.OperationType{admissionV1.Create, admissionV1.Update},
+				Rule: admissionV1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"configmaps"},
+				},
+			}},
+		}},
+	}
+
+	client := fake.NewSimpleClientset(
+		&coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "payments", Labels: map[string]string{"team": "payments"}}},
+		&coreV1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "default"}})
+	model := k8s.NewWebHookClient(client).Build(&k8s.Configurations{
+		Validating: []admissionV1.ValidatingWebhookConfiguration{configuration},
+	})
+	if len(model.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(model.Items))
+	}
+	return model.Items[0]
+}
+
+func object(namespace string, labels map[string]string) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{}
+	o.SetAPIVersion("v1")
+	o.SetKind("ConfigMap")
+	o.SetName("settings")
+	o.SetNamespace(namespace)
+	o.SetLabels(labels)
+	return o
+}
+
+func namespace(name string, labels map[string]string) *unstructured.Unstructured {
+	o := &unstructured.Unstructured{}
+	o.SetAPIVersion("v1")
+	o.SetKind("Namespace")
+	o.SetName(name)
+	o.SetLabels(labels)
+	return o
+}
+
+func TestItem(t *testing.T) {
+	notSkipped := admissionV1.MatchCondition{Name: "not-skipped", Expression: `!has(object.metadata.labels) || !("skip" in object.metadata.labels)`}
+	unknownField := admissionV1.MatchCondition{Name: "team", Expression: `object.metadata.labels["team"] == "payments"`}
+	notAdmin := admissionV1.MatchCondition{Name: "not-admin", Expression: `request.userInfo.username != "admin" && !("system:masters" in request.userInfo.groups)`}
+	configmaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	secrets := schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+	tests := []struct {
+		name    string
+		item    printer.PrintItem
+		req     match.Request
+		matched bool
+		reason  string
+		err     string
+	}{
+		{
+			name:    "selected namespace",
+			item:    webhookItem(t, admissionV1.Fail, notSkipped),
+			req:     match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil)},
+			matched: true,
+			reason:  "rules, selectors and matchConditions match",
+		},
+		{
+			name:    "without matchConditions",
+			item:    webhookItem(t, admissionV1.Fail),
+			req:     match.Request{Operation: "UPDATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil)},
+			matched: true,
+			reason:  "rules and selectors match",
+		},
+		{
+			name:   "operation not in the rules",
+			item:   webhookItem(t, admissionV1.Fail),
+			req:    match.Request{Operation: "DELETE", Resource: configmaps, Namespaced: true, OldObject: object("payments", nil)},
+			reason: "no rule matches DELETE v1/configmaps",
+		},
+		{
+			name:   "resource not in the rules",
+			item:   webhookItem(t, admissionV1.Fail),
+			req:    match.Request{Operation: "CREATE", Resource: secrets, Namespaced: true, Object: object("payments", nil)},
+			reason: "no rule matches CREATE v1/secrets",
+		},
+		{
+			name: "namespace labels selected",
+			item: webhookItem(t, admissionV1.Fail),
+			req: match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments-eu", nil),
+				NamespaceObject: namespace("payments-eu", map[string]string{"team": "payments"})},
+			matched: true,
+			reason:  "rules and selectors match",
+		},
+		{
+			name: "namespace not selected",
+			item: webhookItem(t, admissionV1.Fail),
+			req: match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil),
+				NamespaceObject: namespace("payments", nil)},
+			reason: `namespace "payments" is not selected`,
+		},
+		{
+			name:   "namespace unknown",
+			item:   webhookItem(t, admissionV1.Fail),
+			req:    match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments-eu", nil)},
+			reason: `namespace "payments-eu" is unknown`,
+		},
+		{
+			name:   "matchCondition false",
+			item:   webhookItem(t, admissionV1.Fail, notSkipped),
+			req:    match.Request{Operation: "UPDATE", Resource: configmaps, Namespaced: true, Object: object("payments", map[string]string{"skip": "true"})},
+			reason: `matchCondition "not-skipped" is false`,
+		},
+		{
+			name: "matchCondition error failing closed",
+			item: webhookItem(t, admissionV1.Fail, unknownField),
+			req:  match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil)},
+			err:  `cannot evaluate matchCondition "team" (failurePolicy Fail)`,
+		},
+		{
+			name: "matchCondition error failing open",
+			item: webhookItem(t, admissionV1.Ignore, unknownField),
+			req:  match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil)},
+			err:  `cannot evaluate matchCondition "team" (failurePolicy Ignore)`,
+		},
+		{
+			name: "userInfo",
+			item: webhookItem(t, admissionV1.Fail, notAdmin),
+			req: match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil),
+				UserInfo: authenticationV1.UserInfo{Username: "jane", Groups: []string{"system:authenticated"}}},
+			matched: true,
+			reason:  "rules, selectors and matchConditions match",
+		},
+		{
+			name: "userInfo of an admin",
+			item: webhookItem(t, admissionV1.Fail, notAdmin),
+			req: match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil),
+				UserInfo: authenticationV1.UserInfo{Username: "admin", Groups: []string{"system:masters"}}},
+			reason: `matchCondition "not-admin" is false`,
+		},
+		{
+			name: "userInfo unknown",
+			item: webhookItem(t, admissionV1.Fail, notAdmin),
+			req:  match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil)},
+			err:  `cannot evaluate matchCondition "not-admin"`,
+		},
+		{
+			name: "authorizer",
+			item: webhookItem(t, admissionV1.Fail, admissionV1.MatchCondition{Name: "can-escalate", Expression: `authorizer.group("rbac.authorization.k8s.io").resource("clusterroles").check("escalate").allowed()`}),
+			req: match.Request{Operation: "CREATE", Resource: configmaps, Namespaced: true, Object: object("payments", nil),
+				UserInfo: authenticationV1.UserInfo{Username: "jane"}},
+			err: `cannot evaluate matchCondition "can-escalate"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, reason, err := match.Item(tt.item, tt.req)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Fatalf("unexpected error %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Fatalf("error = %v, want it to contain %q", err, tt.err)
+			}
+			if matched != tt.matched {
+				t.Errorf("matched = %v, want %v (%s)", matched, tt.matched, reason)
+			}
+			if !strings.Contains(reason, tt.reason) {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	notAdmin := admissionV1.MatchCondition{Name: "not-admin", Expression: `request.userInfo.username != "admin"`}
+	model := &printer.PrintModel{Items: []printer.PrintItem{webhookItem(t, admissionV1.Fail), webhookItem(t, admissionV1.Fail, notAdmin)}}
+	req := match.Request{
+		Operation:  "CREATE",
+		Resource:   schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		Namespaced: true,
+		Object:     object("payments", nil),
+	}
+
+	results := match.Match(model, req)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if r := results[0]; !r.Matched || r.Unknown {
+		t.Errorf("got %+v, want the webhook without matchConditions to match", r)
+	}
+	if r := results[1]; r.Matched || !r.Unknown || !strings.Contains(r.Reason, `cannot evaluate matchCondition "not-admin"`) {
+		t.Errorf("got %+v, want the webhook on the unknown user to be unknown", r)
+	}
+}
+
+func TestItemPolicy(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	rule := func(operations ...string) []printer.ResourceModel {
+		return []printer.ResourceModel{{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Operations: operations, Resources: []string{"deployments"}}}
+	}
+	deployment := func(name string, labels map[string]string) *unstructured.Unstructured {
+		o := object("payments", labels)
+		o.SetAPIVersion("apps/v1")
+		o.SetKind("Deployment")
+		o.SetName(name)
+		return o
+	}
+	item := printer.PrintItem{
+		Kind:             "Validating",
+		Name:             "replicas",
+		ResourceModels:   rule("CREATE", "UPDATE"),
+		ActiveNamespaces: []string{"payments", "checkout"},
+		Policy: &printer.PrintPolicyItem{Bindings: []printer.PrintPolicyBindingItem{
+			{
+				Name:              "replicas-create",
+				ResourceModels:    rule("CREATE"),
+				ActiveNamespaces:  []string{"payments"},
+				NamespaceSelector: &metaV1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+				ObjectSelector:    &metaV1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+			},
+			{
+				Name:              "replicas-checkout",
+				ResourceModels:    rule("CREATE", "UPDATE"),
+				ActiveNamespaces:  []string{"checkout"},
+				NamespaceSelector: &metaV1.LabelSelector{MatchLabels: map[string]string{"team": "checkout"}},
+			},
+		}},
+	}
+	item.ExcludedResourceModels = rule("CREATE")
+	item.ExcludedResourceModels[0].ResourceNames = []string{"coredns"}
+
+	tests := []struct {
+		name    string
+		item    printer.PrintItem
+		req     match.Request
+		matched bool
+		reason  string
+	}{
+		{
+			name:    "bound",
+			item:    item,
+			req:     match.Request{Operation: "CREATE", Resource: deployments, Namespaced: true, Object: deployment("web", map[string]string{"tier": "web"})},
+			matched: true,
+		},
+		{
+			name:   "excluded by name",
+			item:   item,
+			req:    match.Request{Operation: "CREATE", Resource: deployments, Namespaced: true, Object: deployment("coredns", map[string]string{"tier": "web"})},
+			reason: "CREATE apps/v1/deployments is excluded",
+		},
+		{
+			name:   "no binding selects the object",
+			item:   item,
+			req:    match.Request{Operation: "CREATE", Resource: deployments, Namespaced: true, Object: deployment("api", nil)},
+			reason: "none of the 2 bindings applies the policy",
+		},
+		{
+			name: "binding rules",
+			item: func() printer.PrintItem {
+				i := item
+				i.Policy = &printer.PrintPolicyItem{Bindings: item.Policy.Bindings[:1]}
+				return i
+			}(),
+			req:    match.Request{Operation: "UPDATE", Resource: deployments, Namespaced: true, Object: deployment("web", map[string]string{"tier": "web"})},
+			reason: `binding "replicas-create" does not match UPDATE apps/v1/deployments`,
+		},
+		{
+			name: "binding does not select the namespace",
+			item: func() printer.PrintItem {
+				i := item
+				i.Policy = &printer.PrintPolicyItem{Bindings: item.Policy.Bindings[1:]}
+				return i
+			}(),
+			req: match.Request{Operation: "CREATE", Resource: deployments, Namespaced: true, Object: deployment("web", nil),
+				NamespaceObject: namespace("payments", map[string]string{"team": "payments"})},
+			reason: `binding "replicas-checkout": namespace "payments" is not selected`,
+		},
+		{
+			name: "unbound",
+			item: func() printer.PrintItem {
+				i := item
+				i.Policy = &printer.PrintPolicyItem{}
+				return i
+			}(),
+			req:    match.Request{Operation: "CREATE", Resource: deployments, Namespaced: true, Object: deployment("web", nil)},
+			reason: "no binding applies the policy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, reason, err := match.Item(tt.item, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched != tt.matched {
+				t.Errorf("matched = %v, want %v (%s)", matched, tt.matched, reason)
+			}
+			if !strings.Contains(reason, tt.reason) {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.reason)
+			}
+		})
+	}
+}
+
+func TestItemEquivalent(t *testing.T) {
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	served := []printer.APIResource{
+		{Group: "apps", Version: "v1", Resource: "deployments", Namespaced: true},
+		{Group: "apps", Version: "v1beta1", Resource: "deployments", Namespaced: true},
+		{Group: "extensions", Version: "v1beta1", Resource: "deployments", Namespaced: true},
+	}
+	item := func(matchPolicy, group, version string) printer.PrintItem {
+		return printer.PrintItem{
+			Kind:        "Validating",
+			Name:        "legacy",
+			MatchPolicy: matchPolicy,
+			ResourceModels: []printer.ResourceModel{{
+				APIGroups:   []string{group},
+				APIVersions: []string{version},
+				Operations:  []string{"CREATE"},
+				Resources:   []string{"deployments"},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		item      printer.PrintItem
+		resources []printer.APIResource
+		matched   bool
+		reason    string
+	}{
+		{
+			name:      "equivalent version",
+			item:      item("Equivalent", "apps", "v1beta1"),
+			resources: served,
+			matched:   true,
+			reason:    "rules and selectors match as equivalent apps/v1beta1/deployments",
+		},
+		{
+			name:      "equivalent group",
+			item:      item("Equivalent", "extensions", "v1beta1"),
+			resources: served,
+			matched:   true,
+			reason:    "as equivalent extensions/v1beta1/deployments",
+		},
+		{
+			name:      "exact",
+			item:      item("Exact", "apps", "v1beta1"),
+			resources: served,
+			reason:    "no rule matches CREATE apps/v1/deployments",
+		},
+		{
+			name:      "equivalent version not served",
+			item:      item("Equivalent", "apps", "v1beta1"),
+			resources: served[:1],
+			reason:    "no rule matches CREATE apps/v1/deployments",
+		},
+		{
+			name: "policy binding",
+			item: func() printer.PrintItem {
+				i := item("Equivalent", "apps", "v1beta1")
+				i.Policy = &printer.PrintPolicyItem{Bindings: []printer.PrintPolicyBindingItem{{Name: "legacy", ResourceModels: i.ResourceModels}}}
+				return i
+			}(),
+			resources: served,
+			matched:   true,
+			reason:    "as equivalent apps/v1beta1/deployments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := match.Request{
+				Operation:   "CREATE",
+				Resource:    deployments,
+				Namespaced:  true,
+				Object:      object("payments", nil),
+				Equivalents: match.EquivalentResources(deployments, tt.resources),
+			}
+			matched, reason, err := match.Item(tt.item, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched != tt.matched {
+				t.Errorf("matched = %v, want %v (%s)", matched, tt.matched, reason)
+			}
+			if !strings.Contains(reason, tt.reason) {
+				t.Errorf("reason = %q, want it to contain %q", reason, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"strings"
+)
+
+// operations are the admission operations in the order they are listed.
+var operations = []string{"CREATE", "UPDATE", "DELETE", "CONNECT"}
+
+// connectSubresources are the subresources requested through CONNECT.
+var connectSubresources = map[string]bool{"exec": true, "attach": true, "portforward": true, "proxy": true}
+
+// movedGroups are the groups resources were moved between, which the API
+// server treats as equivalent for webhooks with matchPolicy Equivalent.
+var movedGroups = map[schema.GroupResource][]string{
+	{Group: "extensions", Resource: "deployments"}:            {"apps"},
+	{Group: "extensions", Resource: "daemonsets"}:             {"apps"},
+	{Group: "extensions", Resource: "replicasets"}:            {"apps"},
+	{Group: "extensions", Resource: "networkpolicies"}:        {"networking.k8s.io"},
+	{Group: "extensions", Resource: "ingresses"}:              {"networking.k8s.io"},
+	{Group: "apps", Resource: "deployments"}:                  {"extensions"},
+	{Group: "apps", Resource: "daemonsets"}:                   {"extensions"},
+	{Group: "apps", Resource: "replicasets"}:                  {"extensions"},
+	{Group: "networking.k8s.io", Resource: "ingresses"}:       {"extensions"},
+	{Group: "networking.k8s.io", Resource: "networkpolicies"}: {"extensions"},
+}
+
+// Resources inverts the given model by API resource: for every operation
+// the given resources support it lists the mutating and validating
+// webhooks and policies whose rules intercept it. Namespace and object
+// selectors are not taken into account. Subresources are only included
+// when asked for.
+func Resources(model *printer.PrintModel, resources []printer.APIResource, subresources bool) *printer.PrintResourceModel {
+	served := servedVersions(resources)
+
+	result := &printer.PrintResourceModel{}
+	for _, r := range resources {
+		if r.Subresource() && !subresources {
+			continue
+		}
+		gvr := schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+		equivalents := equivalentResources(gvr, served)
+
+		for _, operation := range Operations(r) {
+			resourceItem := printer.PrintResourceItem{APIResource: r, Operation: operation}
+			for _, item := range model.Items {
+				// a policy no binding applies intercepts nothing, its
+				// rules only tell what it would once bound
+				if item.Policy != nil && len(item.Policy.Bindings) == 0 {
+					continue
+				}
+				if !interceptsResource(item, operation, gvr, equivalents, r.Namespaced) {
+					continue
+				}
+				if item.Kind == "Mutating" {
+					resourceItem.Mutating = append(resourceItem.Mutating, itemName(item))
+				} else {
+					resourceItem.Validating = append(resourceItem.Validating, itemName(item))
+				}
+			}
+			result.Items = append(result.Items, resourceItem)
+		}
+	}
+	return result
+}
+
+// Operations returns the admission operations the verbs of a resource
+// can trigger.
+func Operations(r printer.APIResource) []string {
+	if parts := strings.SplitN(r.Resource, "/", 2); len(parts) == 2 && connectSubresources[parts[1]] {
+		return []string{"CONNECT"}
+	}
+
+	supported := map[string]bool{}
+	for _, verb := range r.Verbs {
+		switch verb {
+		case "create":
+			supported["CREATE"] = true
+		case "update", "patch":
+			supported["UPDATE"] = true
+		case "delete", "deletecollection":
+			supported["DELETE"] = true
+		}
+	}
+
+	var result []string
+	for _, operation := range operations {
+		if supported[operation] {
+			result = append(result, operation)
+		}
+	}
+	return result
+}
+
+// RuleMatches tells whether the given rule intercepts the operation on the
+// resource. ResourceNames are not taken into account.
+func RuleMatches(rm printer.ResourceModel, operation string, gvr schema.GroupVersionResource, namespaced bool) bool {
+	if !matchesAny(rm.Operations, operation) ||
+		!matchesAny(rm.APIGroups, gvr.Group) ||
+		!matchesAny(rm.APIVersions, gvr.Version) ||
+		!resourceMatches(rm.Resources, gvr.Resource) {
+		return false
+	}
+	return !(rm.Scope == "Cluster" && namespaced) && !(rm.Scope == "Namespaced" && !namespaced)
+}
+
+// interceptsResource tells whether any rule of the item, and none of its
+// exclusions, matches the resource or, with matchPolicy Equivalent, one
+// of its equivalents.
+func interceptsResource(item printer.PrintItem, operation string, gvr schema.GroupVersionResource, equivalents []schema.GroupVersionResource, namespaced bool) bool {
+	candidates := []schema.GroupVersionResource{gvr}
+	if item.MatchPolicy == "Equivalent" {
+		candidates = append(candidates, equivalents...)
+	}
+	for _, candidate := range candidates {
+		if anyRuleMatches(item.ResourceModels, operation, candidate, namespaced, false) &&
+			!anyRuleMatches(item.ExcludedResourceModels, operation, candidate, namespaced, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyRuleMatches tells whether any of the rules matches the resource,
+// leaving out the ones restricted to named objects when asked to.
+func anyRuleMatches(rules []printer.ResourceModel, operation string, gvr schema.GroupVersionResource, namespaced, unnamed bool) bool {
+	for _, rm := range rules {
+		if unnamed && len(rm.ResourceNames) > 0 {
+			continue
+		}
+		if RuleMatches(rm, operation, gvr, namespaced) {
+			return true
+		}
+	}
+	return false
+}
+
+// EquivalentResources returns the other versions of the given resource
+// among the given served resources, which webhooks and policies with
+// matchPolicy Equivalent also intercept.
+func EquivalentResources(gvr schema.GroupVersionResource, resources []printer.APIResource) []schema.GroupVersionResource {
+	return equivalentResources(gvr, servedVersions(resources))
+}
+
+// servedVersions returns the versions every resource is served in.
+func servedVersions(resources []printer.APIResource) map[schema.GroupResource][]string {
+	served := map[schema.GroupResource][]string{}
+	for _, r := range resources {
+		gr := schema.GroupResource{Group: r.Group, Resource: r.Resource}
+		served[gr] = append(served[gr], r.Version)
+	}
+	return served
+}
+
+// equivalentResources returns the other served versions of a resource,
+// in its own group or a group it was moved between.
+func equivalentResources(gvr schema.GroupVersionResource, served map[schema.GroupResource][]string) []schema.GroupVersionResource {
+	resource, subresource := gvr.Resource, ""
+	if parts := strings.SplitN(gvr.Resource, "/", 2); len(parts) == 2 {
+		resource, subresource = parts[0], "/"+parts[1]
+	}
+
+	groups := append([]string{gvr.Group}, movedGroups[schema.GroupResource{Group: gvr.Group, Resource: resource}]...)
+	var result []schema.GroupVersionResource
+	for _, group := range groups {
+		for _, version := range served[schema.GroupResource{Group: group, Resource: gvr.Resource}] {
+			if group == gvr.Group && version == gvr.Version {
+				continue
+			}
+			result = append(result, schema.GroupVersionResource{Group: group, Version: version, Resource: resource + subresource})
+		}
+	}
+	return result
+}
+
+// itemName names a webhook as configuration/webhook and a policy by its
+// own name.
+func itemName(item printer.PrintItem) string {
+	if item.Policy != nil {
+		return item.Name + " (policy)"
+	}
+	return item.Name + "/" + item.Webhook.Name
+}
@@ -0,0 +1,116 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match_test
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"strings"
+	"testing"
+)
+
+func TestResources(t *testing.T) {
+	verbs := []string{"create", "update", "patch", "delete", "list"}
+	resources := []printer.APIResource{
+		{Version: "v1", Resource: "pods", Kind: "Pod", Namespaced: true, Verbs: verbs},
+		{Version: "v1", Resource: "pods/exec", Kind: "PodExecOptions", Namespaced: true, Verbs: []string{"create", "get"}},
+		{Group: "apps", Version: "v1", Resource: "deployments", Kind: "Deployment", Namespaced: true, Verbs: verbs},
+		{Group: "extensions", Version: "v1beta1", Resource: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"create"}},
+	}
+	rule := func(group, version, resource string, operations ...string) []printer.ResourceModel {
+		return []printer.ResourceModel{{
+			APIGroups:   []string{group},
+			APIVersions: []string{version},
+			Resources:   []string{resource},
+			Operations:  operations,
+			Scope:       "*",
+		}}
+	}
+	model := &printer.PrintModel{Items: []printer.PrintItem{
+		{Kind: "Mutating", Name: "injector", Webhook: printer.PrintWebhookItem{Name: "pods.io"}, ResourceModels: rule("", "v1", "*", "CREATE")},
+		{Kind: "Validating", Name: "exec", Webhook: printer.PrintWebhookItem{Name: "exec.io"}, ResourceModels: rule("", "*", "pods/exec", "CONNECT")},
+		{Kind: "Validating", Name: "legacy", Webhook: printer.PrintWebhookItem{Name: "equivalent.io"}, MatchPolicy: "Equivalent", ResourceModels: rule("extensions", "v1beta1", "deployments", "CREATE")},
+		{Kind: "Validating", Name: "legacy", Webhook: printer.PrintWebhookItem{Name: "exact.io"}, MatchPolicy: "Exact", ResourceModels: rule("extensions", "v1beta1", "deployments", "CREATE")},
+		{Kind: "Policy", Name: "replicas", Policy: &printer.PrintPolicyItem{Bindings: []printer.PrintPolicyBindingItem{{Name: "replicas"}}}, ResourceModels: rule("apps", "v1", "deployments", "UPDATE")},
+		// unbound policies intercept nothing
+		{Kind: "Policy", Name: "unbound", Policy: &printer.PrintPolicyItem{}, ResourceModels: rule("apps", "v1", "deployments", "DELETE")},
+	}}
+
+	tests := []struct {
+		name         string
+		subresources bool
+		want         []string
+	}{
+		{
+			name: "resources",
+			want: []string{
+				"v1/pods CREATE: [injector/pods.io] []",
+				"v1/pods UPDATE: [] []",
+				"v1/pods DELETE: [] []",
+				"apps/v1/deployments CREATE: [] [legacy/equivalent.io]",
+				"apps/v1/deployments UPDATE: [] [replicas (policy)]",
+				"apps/v1/deployments DELETE: [] []",
+				"extensions/v1beta1/deployments CREATE: [] [legacy/equivalent.io legacy/exact.io]",
+			},
+		},
+		{
+			name:         "subresources",
+			subresources: true,
+			want: []string{
+				"v1/pods CREATE: [injector/pods.io] []",
+				"v1/pods UPDATE: [] []",
+				"v1/pods DELETE: [] []",
+				"v1/pods/exec CONNECT: [] [exec/exec.io]",
+				"apps/v1/deployments CREATE: [] [legacy/equivalent.io]",
+				"apps/v1/deployments UPDATE: [] [replicas (policy)]",
+				"apps/v1/deployments DELETE: [] []",
+				"extensions/v1beta1/deployments CREATE: [] [legacy/equivalent.io legacy/exact.io]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, item := range match.Resources(model, resources, tt.subresources).Items {
+				got = append(got, fmt.Sprintf("%s/%s %s: %v %v", item.GroupVersion(), item.Resource, item.Operation, item.Mutating, item.Validating))
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestOperations(t *testing.T) {
+	for _, tt := range []struct {
+		resource string
+		verbs    []string
+		want     string
+	}{
+		{resource: "pods", verbs: []string{"create", "delete", "get", "list", "patch", "update", "watch"}, want: "CREATE,UPDATE,DELETE"},
+		{resource: "pods/status", verbs: []string{"get", "patch"}, want: "UPDATE"},
+		{resource: "pods/portforward", verbs: []string{"create", "get"}, want: "CONNECT"},
+		{resource: "componentstatuses", verbs: []string{"get", "list"}},
+	} {
+		got := strings.Join(match.Operations(printer.APIResource{Resource: tt.resource, Verbs: tt.verbs}), ",")
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.resource, got, tt.want)
+		}
+	}
+}
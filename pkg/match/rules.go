@@ -0,0 +1,207 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match
+
+import (
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"strings"
+)
+
+// Intersect returns the rule matching what both given rules match, as a
+// policy binding's resourceRules narrow the policy's, and false when they
+// have nothing in common.
+func Intersect(a, b printer.ResourceModel) (printer.ResourceModel, bool) {
+	rm := printer.ResourceModel{
+		APIGroups:   intersectValues(a.APIGroups, b.APIGroups),
+		APIVersions: intersectValues(a.APIVersions, b.APIVersions),
+		Operations:  intersectValues(a.Operations, b.Operations),
+		Resources:   intersectResources(a.Resources, b.Resources),
+	}
+	if len(rm.APIGroups) == 0 || len(rm.APIVersions) == 0 || len(rm.Operations) == 0 || len(rm.Resources) == 0 {
+		return printer.ResourceModel{}, false
+	}
+
+	switch {
+	case isAllScopes(a.Scope):
+		rm.Scope = b.Scope
+	case isAllScopes(b.Scope) || a.Scope == b.Scope:
+		rm.Scope = a.Scope
+	default:
+		return printer.ResourceModel{}, false
+	}
+
+	switch {
+	case len(a.ResourceNames) == 0:
+		rm.ResourceNames = b.ResourceNames
+	case len(b.ResourceNames) == 0:
+		rm.ResourceNames = a.ResourceNames
+	default:
+		rm.ResourceNames = intersectValues(a.ResourceNames, b.ResourceNames)
+		if len(rm.ResourceNames) == 0 {
+			return printer.ResourceModel{}, false
+		}
+	}
+	return rm, true
+}
+
+// Exclude removes what the given exclusions, such as a policy's
+// excludeResourceRules, match from the given rules. Rules are narrowed
+// down to the resources or operations that are not excluded, and dropped
+// when none are left. Exclusions that overlap a rule in a way narrowing it
+// cannot express, such as a single resource excluded from "*", are
+// returned to be checked on their own.
+func Exclude(rules, exclusions []printer.ResourceModel) ([]printer.ResourceModel, []printer.ResourceModel) {
+	var remaining, partial []printer.ResourceModel
+	added := map[int]bool{}
+
+	for _, rm := range rules {
+		kept := true
+		for i, e := range exclusions {
+			if _, ok := Intersect(rm, e); !ok {
+				continue
+			}
+			rm = narrow(rm, e)
+			if len(rm.Resources) == 0 || len(rm.Operations) == 0 {
+				kept = false
+				break
+			}
+			if _, ok := Intersect(rm, e); ok && !added[i] {
+				partial = append(partial, e)
+				added[i] = true
+			}
+		}
+		if kept {
+			remaining = append(remaining, rm)
+		}
+	}
+	return remaining, partial
+}
+
+// narrow removes the resources or operations the exclusion matches from
+// the rule, when it covers the rule's groups, versions and scope.
+func narrow(rm, e printer.ResourceModel) printer.ResourceModel {
+	if len(e.ResourceNames) > 0 || !covers(e.APIGroups, rm.APIGroups) || !covers(e.APIVersions, rm.APIVersions) ||
+		!(isAllScopes(e.Scope) || e.Scope == rm.Scope) {
+		return rm
+	}
+
+	if covers(e.Operations, rm.Operations) {
+		var resources []string
+		for _, r := range rm.Resources {
+			if !resourceMatches(e.Resources, r) {
+				resources = append(resources, r)
+			}
+		}
+		rm.Resources = resources
+		return rm
+	}
+
+	for _, r := range rm.Resources {
+		if !resourceMatches(e.Resources, r) {
+			return rm
+		}
+	}
+	var ops []string
+	for _, op := range rm.Operations {
+		if op == "*" || !matchesAny(e.Operations, op) {
+			ops = append(ops, op)
+		}
+	}
+	rm.Operations = ops
+	return rm
+}
+
+// covers tells whether the values of an exclusion match every value of a
+// rule, which a wildcard in the rule is only matched by another wildcard.
+func covers(exclusion, values []string) bool {
+	if containsString(exclusion, "*") {
+		return true
+	}
+	for _, v := range values {
+		if v == "*" || !matchesAny(exclusion, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func intersectValues(a, b []string) []string {
+	if containsString(a, "*") {
+		return b
+	}
+	if containsString(b, "*") {
+		return a
+	}
+	var result []string
+	for _, v := range a {
+		for _, w := range b {
+			if strings.EqualFold(v, w) {
+				result = append(result, v)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// intersectResources keeps the narrower of every two resources, with or
+// without wildcards, of which one matches the other.
+func intersectResources(a, b []string) []string {
+	var result []string
+	for _, x := range a {
+		for _, y := range b {
+			r := ""
+			switch {
+			case resourceMatches([]string{x}, y):
+				r = y
+			case resourceMatches([]string{y}, x):
+				r = x
+			default:
+				continue
+			}
+			if !containsString(result, r) {
+				result = append(result, r)
+			}
+		}
+	}
+	return result
+}
+
+func isAllScopes(scope string) bool {
+	return scope == "" || scope == "*"
+}
+
+// ruleKey identifies a rule by what it matches.
+func ruleKey(rm printer.ResourceModel) string {
+	return fmt.Sprint(rm.APIGroups, rm.APIVersions, rm.Operations, rm.Resources, rm.ResourceNames, rm.Scope)
+}
+
+// Union appends the rules of b that are not in a already.
+func Union(a, b []printer.ResourceModel) []printer.ResourceModel {
+	seen := map[string]bool{}
+	for _, rm := range a {
+		seen[ruleKey(rm)] = true
+	}
+	for _, rm := range b {
+		if !seen[ruleKey(rm)] {
+			seen[ruleKey(rm)] = true
+			a = append(a, rm)
+		}
+	}
+	return a
+}
@@ -0,0 +1,173 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match_test
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"reflect"
+	"testing"
+)
+
+// appsRule returns a rule on the given apps/v1 resources.
+func appsRule(operations []string, resources ...string) printer.ResourceModel {
+	return printer.ResourceModel{
+		APIGroups:   []string{"apps"},
+		APIVersions: []string{"v1"},
+		Operations:  operations,
+		Resources:   resources,
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	all := []string{"*"}
+	createUpdate := []string{"CREATE", "UPDATE"}
+	named := appsRule(all, "deployments")
+	named.ResourceNames = []string{"coredns"}
+	namespaced := appsRule(all, "*")
+	namespaced.Scope = "Namespaced"
+	cluster := appsRule(all, "*")
+	cluster.Scope = "Cluster"
+
+	tests := []struct {
+		name string
+		a, b printer.ResourceModel
+		want *printer.ResourceModel
+	}{
+		{
+			name: "operations",
+			a:    appsRule(createUpdate, "deployments"),
+			b:    appsRule([]string{"UPDATE", "DELETE"}, "deployments"),
+			want: &printer.ResourceModel{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Operations: []string{"UPDATE"}, Resources: []string{"deployments"}},
+		},
+		{
+			name: "wildcard resources",
+			a:    appsRule(all, "*"),
+			b:    appsRule(createUpdate, "deployments", "deployments/scale"),
+			want: &printer.ResourceModel{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Operations: createUpdate, Resources: []string{"deployments"}},
+		},
+		{
+			name: "disjoint resources",
+			a:    appsRule(all, "deployments"),
+			b:    appsRule(all, "statefulsets"),
+		},
+		{
+			name: "resource names",
+			a:    appsRule(createUpdate, "*"),
+			b:    named,
+			want: &printer.ResourceModel{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Operations: createUpdate, Resources: []string{"deployments"}, ResourceNames: []string{"coredns"}},
+		},
+		{
+			name: "scope",
+			a:    appsRule(createUpdate, "deployments"),
+			b:    namespaced,
+			want: &printer.ResourceModel{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Operations: createUpdate, Resources: []string{"deployments"}, Scope: "Namespaced"},
+		},
+		{
+			name: "disjoint scopes",
+			a:    namespaced,
+			b:    cluster,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := match.Intersect(tt.a, tt.b)
+			switch {
+			case tt.want == nil && ok:
+				t.Errorf("got %+v, want no intersection", got)
+			case tt.want != nil && !ok:
+				t.Errorf("got no intersection, want %+v", *tt.want)
+			case tt.want != nil && !reflect.DeepEqual(got, *tt.want):
+				t.Errorf("got %+v, want %+v", got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestExclude(t *testing.T) {
+	all := []string{"*"}
+	createUpdate := []string{"CREATE", "UPDATE"}
+	named := appsRule(all, "deployments")
+	named.ResourceNames = []string{"coredns"}
+
+	tests := []struct {
+		name       string
+		rules      []printer.ResourceModel
+		exclusions []printer.ResourceModel
+		want       []printer.ResourceModel
+		partial    []printer.ResourceModel
+	}{
+		{
+			name:  "nothing excluded",
+			rules: []printer.ResourceModel{appsRule(createUpdate, "deployments")},
+			want:  []printer.ResourceModel{appsRule(createUpdate, "deployments")},
+		},
+		{
+			name:       "excluded resource",
+			rules:      []printer.ResourceModel{appsRule(createUpdate, "deployments", "statefulsets")},
+			exclusions: []printer.ResourceModel{appsRule(all, "statefulsets")},
+			want:       []printer.ResourceModel{appsRule(createUpdate, "deployments")},
+		},
+		{
+			name:       "excluded operation",
+			rules:      []printer.ResourceModel{appsRule(createUpdate, "deployments")},
+			exclusions: []printer.ResourceModel{appsRule([]string{"UPDATE"}, "deployments")},
+			want:       []printer.ResourceModel{appsRule([]string{"CREATE"}, "deployments")},
+		},
+		{
+			name:       "rule excluded entirely",
+			rules:      []printer.ResourceModel{appsRule(createUpdate, "deployments"), appsRule(all, "daemonsets")},
+			exclusions: []printer.ResourceModel{appsRule(all, "deployments")},
+			want:       []printer.ResourceModel{appsRule(all, "daemonsets")},
+		},
+		{
+			name:       "resource excluded from a wildcard",
+			rules:      []printer.ResourceModel{appsRule(createUpdate, "*")},
+			exclusions: []printer.ResourceModel{appsRule(all, "deployments")},
+			want:       []printer.ResourceModel{appsRule(createUpdate, "*")},
+			partial:    []printer.ResourceModel{appsRule(all, "deployments")},
+		},
+		{
+			name:       "excluded by name",
+			rules:      []printer.ResourceModel{appsRule(createUpdate, "deployments")},
+			exclusions: []printer.ResourceModel{named},
+			want:       []printer.ResourceModel{appsRule(createUpdate, "deployments")},
+			partial:    []printer.ResourceModel{named},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, partial := match.Exclude(tt.rules, tt.exclusions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got rules %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(partial, tt.partial) {
+				t.Errorf("got exclusions %+v, want %+v", partial, tt.partial)
+			}
+		})
+	}
+}
+
+func TestUnion(t *testing.T) {
+	deployments, daemonsets := appsRule([]string{"*"}, "deployments"), appsRule([]string{"*"}, "daemonsets")
+	got := match.Union([]printer.ResourceModel{deployments}, []printer.ResourceModel{daemonsets, deployments})
+	if want := []printer.ResourceModel{deployments, daemonsets}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
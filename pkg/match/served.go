@@ -0,0 +1,85 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CheckServed sets the group/version/resource combinations of every rule
+// of the given model whose resource none of the rule's group versions
+// serves. Requests for such a resource are never made, so that part of
+// the rule never fires, whatever the matchPolicy. A resource served in
+// another of the rule's group versions is not reported, as every group is
+// combined with every version of a rule. Combinations in the given group
+// versions that failed discovery may be served, they are set as unknown
+// instead.
+func CheckServed(model *printer.PrintModel, resources []printer.APIResource, failed []schema.GroupVersion) {
+	for i := range model.Items {
+		for j := range model.Items[i].ResourceModels {
+			rm := &model.Items[i].ResourceModels[j]
+			rm.Unserved, rm.Unknown = nil, nil
+			for _, resource := range rm.Resources {
+				var unserved, unknown []string
+				servedSomewhere := false
+				for _, group := range rm.APIGroups {
+					for _, version := range rm.APIVersions {
+						gvr := schema.GroupVersionResource{Group: group, Version: version, Resource: resource}
+						switch {
+						case served(group, version, resource, resources):
+							servedSomewhere = true
+						case undiscovered(group, version, failed):
+							unknown = append(unknown, resourceString(gvr))
+						default:
+							unserved = append(unserved, resourceString(gvr))
+						}
+					}
+				}
+
+				switch {
+				case servedSomewhere:
+				case len(unknown) > 0:
+					rm.Unknown = append(rm.Unknown, unknown...)
+				default:
+					rm.Unserved = append(rm.Unserved, unserved...)
+				}
+			}
+		}
+	}
+}
+
+// undiscovered tells whether any of the failed group versions is in the
+// given group and version, either of which may be a wildcard.
+func undiscovered(group, version string, failed []schema.GroupVersion) bool {
+	for _, gv := range failed {
+		if (group == "*" || group == gv.Group) && (version == "*" || version == gv.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+func served(group, version, resource string, resources []printer.APIResource) bool {
+	rm := printer.ResourceModel{APIGroups: []string{group}, APIVersions: []string{version}, Resources: []string{resource}}
+	for _, r := range resources {
+		if resourceInRule(rm, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package match_test
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"reflect"
+	"testing"
+)
+
+func TestCheckServed(t *testing.T) {
+	resources := []printer.APIResource{
+		{Version: "v1", Resource: "pods", Namespaced: true},
+		{Group: "apps", Version: "v1", Resource: "deployments", Namespaced: true},
+		{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses", Namespaced: true},
+	}
+	failed := []schema.GroupVersion{{Group: "metrics.k8s.io", Version: "v1beta1"}}
+
+	tests := []struct {
+		name    string
+		rule    printer.ResourceModel
+		want    []string
+		unknown []string
+	}{
+		{
+			name: "served",
+			rule: printer.ResourceModel{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"deployments"}},
+		},
+		{
+			name: "wildcards",
+			rule: printer.ResourceModel{APIGroups: []string{"*"}, APIVersions: []string{"*"}, Resources: []string{"*"}},
+		},
+		{
+			name: "removed beta version",
+			rule: printer.ResourceModel{APIGroups: []string{"extensions"}, APIVersions: []string{"v1beta1"}, Resources: []string{"ingresses"}},
+			want: []string{"extensions/v1beta1/ingresses"},
+		},
+		{
+			name: "one of the versions",
+			rule: printer.ResourceModel{APIGroups: []string{"apps"}, APIVersions: []string{"v1", "v1beta2"}, Resources: []string{"deployments"}},
+		},
+		{
+			name: "mixed groups",
+			rule: printer.ResourceModel{APIGroups: []string{"", "apps"}, APIVersions: []string{"v1"}, Resources: []string{"pods", "deployments"}},
+		},
+		{
+			name: "mixed groups with a resource of neither",
+			rule: printer.ResourceModel{APIGroups: []string{"", "apps"}, APIVersions: []string{"v1"}, Resources: []string{"pods", "replicationcontrollers"}},
+			want: []string{"v1/replicationcontrollers", "apps/v1/replicationcontrollers"},
+		},
+		{
+			name:    "undiscovered group version",
+			rule:    printer.ResourceModel{APIGroups: []string{"metrics.k8s.io"}, APIVersions: []string{"v1beta1"}, Resources: []string{"pods"}},
+			unknown: []string{"metrics.k8s.io/v1beta1/pods"},
+		},
+		{
+			name:    "wildcard version of an undiscovered group",
+			rule:    printer.ResourceModel{APIGroups: []string{"metrics.k8s.io", "policy"}, APIVersions: []string{"*"}, Resources: []string{"nodes"}},
+			unknown: []string{"metrics.k8s.io/*/nodes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := &printer.PrintModel{Items: []printer.PrintItem{{ResourceModels: []printer.ResourceModel{tt.rule}}}}
+			match.CheckServed(model, resources, failed)
+			rm := model.Items[0].ResourceModels[0]
+			if !reflect.DeepEqual(rm.Unserved, tt.want) {
+				t.Errorf("got unserved %#v, want %#v", rm.Unserved, tt.want)
+			}
+			if !reflect.DeepEqual(rm.Unknown, tt.unknown) {
+				t.Errorf("got unknown %#v, want %#v", rm.Unknown, tt.unknown)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package matchcondition compiles and evaluates the CEL matchConditions of
+// admission webhooks and policies the way the API server does.
+package matchcondition
+
+import (
+	"errors"
+	"fmt"
+	"github.com/google/cel-go/cel"
+	plugincel "k8s.io/apiserver/pkg/admission/plugin/cel"
+	"k8s.io/apiserver/pkg/cel/environment"
+)
+
+// Variables are the variables the API server declares for matchConditions.
+// request and namespaceObject are typed as the API server types them,
+// object, oldObject and params are dynamically typed as their schema is
+// not known here, so compiling catches misspelled request fields but not
+// misspelled object fields. authorizer is declared but never set, as the
+// authorizer of the API server cannot be called from here, so expressions
+// using it compile but cannot be evaluated.
+var Variables = []string{"object", "oldObject", "request", "namespaceObject", "params", "authorizer"}
+
+// compiler compiles expressions in the environment of the API server,
+// with the Kubernetes CEL libraries, such as the authorizer, quantity,
+// regex, URL and string extension functions.
+var compiler = plugincel.NewCompiler(environment.MustBaseEnvSet(environment.DefaultCompatibilityVersion(), false))
+
+// condition is a matchCondition expression, which must evaluate to bool.
+type condition string
+
+func (c condition) GetExpression() string {
+	return string(c)
+}
+
+func (c condition) ReturnTypes() []*cel.Type {
+	return []*cel.Type{cel.BoolType}
+}
+
+// Compile type-checks the given expression and returns it ready to be
+// evaluated. Expressions are compiled as stored ones, with every library
+// of the API server's release, as the configurations they are read from
+// were already accepted by the API server.
+func Compile(expression string) (cel.Program, error) {
+	result := compiler.CompileCELExpression(condition(expression),
+		plugincel.OptionalVariableDeclarations{HasParams: true, HasAuthorizer: true},
+		environment.StoredExpressions)
+	if result.Error != nil {
+		return nil, errors.New(result.Error.Detail)
+	}
+	return result.Program, nil
+}
+
+// Evaluate compiles the given expression and evaluates it against the
+// given variables, missing ones but the authorizer are null.
+func Evaluate(expression string, vars map[string]interface{}) (bool, error) {
+	program, err := Compile(expression)
+	if err != nil {
+		return false, err
+	}
+
+	// the authorizer is left unset rather than null, so that expressions
+	// using it fail with a missing attribute
+	activation := map[string]interface{}{}
+	for _, v := range Variables {
+		if v != "authorizer" {
+			activation[v] = nil
+		}
+	}
+	for k, v := range vars {
+		activation[k] = v
+	}
+
+	out, _, err := program.Eval(activation)
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("evaluated to %v, not bool", out.Value())
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package matchcondition
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		expression string
+		err        string
+	}{
+		{expression: `request.operation == "CREATE"`},
+		{expression: `has(object.metadata.labels) && object.metadata.labels["app"] == "web"`},
+		{expression: `request.operation ==`, err: "Syntax error"},
+		{expression: `unknown.field == 1`, err: "undeclared reference"},
+		{expression: `1 + 1`, err: "must evaluate to bool"},
+		{expression: `request.usrInfo.username == "admin"`, err: "undefined field"},
+		{expression: `request.userInfo.username.lowerAscii() == "admin"`},
+		{expression: `quantity(object.spec.resources.limits.memory).isGreaterThan(quantity("1Gi"))`},
+		{expression: `authorizer.group("").resource("pods").check("create").allowed()`},
+		{expression: `params.maxReplicas >= object.spec.replicas`},
+	}
+
+	for _, tt := range tests {
+		_, err := Compile(tt.expression)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.expression, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error = %v, want it to contain %q", tt.expression, err, tt.err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	vars := map[string]interface{}{
+		"request": map[string]interface{}{
+			"operation": "UPDATE",
+			"namespace": "payments",
+		},
+		"object": map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"app": "web"},
+			},
+		},
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+		err        string
+	}{
+		{expression: `request.operation == "UPDATE"`, want: true},
+		{expression: `request.namespace != "payments"`, want: false},
+		{expression: `object.metadata.labels["app"] == "web"`, want: true},
+		// oldObject is null on CREATE and on this request
+		{expression: `oldObject == null`, want: true},
+		{expression: `object.metadata.labels["missing"] == "x"`, err: "no such key"},
+		{expression: `quantity("1536Mi").isGreaterThan(quantity("1Gi"))`, want: true},
+		{expression: `"payments".upperAscii() == "PAYMENTS"`, want: true},
+		// the authorizer of the API server is not available
+		{expression: `authorizer.group("").resource("pods").check("create").allowed()`, err: "no such attribute"},
+	}
+
+	for _, tt := range tests {
+		got, err := Evaluate(tt.expression, vars)
+		switch {
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: error = %v, want it to contain %q", tt.expression, err, tt.err)
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.expression, err)
+		case got != tt.want:
+			t.Errorf("%s = %v, want %v", tt.expression, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"github.com/pterm/pterm"
+	"sort"
+)
+
+var colors = map[string]pterm.Color{
+	"black":        pterm.FgBlack,
+	"red":          pterm.FgRed,
+	"green":        pterm.FgGreen,
+	"yellow":       pterm.FgYellow,
+	"blue":         pterm.FgBlue,
+	"magenta":      pterm.FgMagenta,
+	"cyan":         pterm.FgCyan,
+	"white":        pterm.FgWhite,
+	"gray":         pterm.FgGray,
+	"lightred":     pterm.FgLightRed,
+	"lightgreen":   pterm.FgLightGreen,
+	"lightyellow":  pterm.FgLightYellow,
+	"lightblue":    pterm.FgLightBlue,
+	"lightmagenta": pterm.FgLightMagenta,
+	"lightcyan":    pterm.FgLightCyan,
+	"lightwhite":   pterm.FgLightWhite,
+}
+
+// ColorScheme names the colours healthy, warning and critical values
+// are printed in.
+type ColorScheme struct {
+	OK       string `json:"ok,omitempty"`
+	Warning  string `json:"warning,omitempty"`
+	Critical string `json:"critical,omitempty"`
+}
+
+// DefaultColorScheme is the traffic light scheme used unless configured otherwise.
+var DefaultColorScheme = ColorScheme{
+	OK:       "green",
+	Warning:  "yellow",
+	Critical: "red",
+}
+
+// Validate ensures that every colour of the scheme is known.
+func (c ColorScheme) Validate() error {
+	for _, name := range []string{c.OK, c.Warning, c.Critical} {
+		if _, ok := colors[name]; !ok {
+			var known []string
+			for k := range colors {
+				known = append(known, k)
+			}
+			sort.Strings(known)
+			return fmt.Errorf("unknown colour %q, must be one of %v", name, known)
+		}
+	}
+	return nil
+}
+
+func (c ColorScheme) style(name string) *pterm.Style {
+	return pterm.NewStyle(colors[name])
+}
@@ -0,0 +1,100 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{
+	"kind", "configuration", "webhook",
+	"service_namespace", "service_name", "service_path", "service_port", "url",
+	"api_groups", "api_versions", "resources", "operation",
+	"failure_policy", "timeout_seconds", "cert_expiry", "active_namespaces",
+	"expanded_resources",
+}
+
+// printCSV prints the given PrintModel flattened into one row per
+// webhook, rule and operation, separated by the given rune.
+func (p *Printer) printCSV(model *PrintModel, comma rune) error {
+	w := csv.NewWriter(p.out)
+	w.Comma = comma
+
+	if !p.opts.NoHeaders {
+		if err := w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	for _, item := range model.Items {
+		service := item.Webhook.Service
+
+		var path, port, url, timeout, expiry string
+		if service.Path != nil {
+			path = *service.Path
+		}
+		if service.Port != nil {
+			port = strconv.Itoa(int(*service.Port))
+		} else if service.Name != "" {
+			port = "443"
+		}
+		if item.Webhook.URL != nil {
+			url = *item.Webhook.URL
+		}
+		if item.TimeoutSeconds != nil {
+			timeout = strconv.Itoa(int(*item.TimeoutSeconds))
+		}
+		if item.ValidUntil != 0 {
+			expiry = now.Add(item.ValidUntil).UTC().Format("2006-01-02")
+		}
+
+		prefix := []string{item.Kind, item.Name, item.Webhook.Name, service.Namespace, service.Name, path, port, url}
+		suffix := []string{item.FailurePolicy, timeout, expiry, strconv.Itoa(len(item.ActiveNamespaces))}
+
+		rules := item.ResourceModels
+		if len(rules) == 0 {
+			rules = []ResourceModel{{}}
+		}
+		for _, rm := range rules {
+			operations := rm.Operations
+			if len(operations) == 0 {
+				operations = []string{""}
+			}
+			for _, op := range operations {
+				rule := []string{ruleGroups(rm.APIGroups, ";"), strings.Join(rm.APIVersions, ";"), strings.Join(rm.Resources, ";"), op}
+
+				var expanded string
+				if rm.Expanded != nil {
+					expanded = strconv.Itoa(len(rm.Expanded))
+				}
+
+				row := append(append(append([]string{}, prefix...), rule...), suffix...)
+				row = append(row, expanded)
+				if err := w.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
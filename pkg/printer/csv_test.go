@@ -0,0 +1,63 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintCSV(t *testing.T) {
+	expiry := time.Now().Add(400 * 24 * time.Hour).UTC().Format("2006-01-02")
+	rows := []string{
+		"Mutating,sidecar-injector,sidecar.injector.io,mesh,injector,/mutate,443,,core,v1,pods,CREATE,Fail,5," + expiry + ",2,",
+		"Mutating,sidecar-injector,sidecar.injector.io,mesh,injector,/mutate,443,,core,v1,pods,UPDATE,Fail,5," + expiry + ",2,",
+		"Validating,deny-all,deny.all.io,,,,,,apps,v1,*,DELETE,Ignore,,,0,3",
+	}
+
+	tests := []struct {
+		format    string
+		noHeaders bool
+		want      string
+	}{
+		{
+			format: "csv",
+			want:   strings.Join(append([]string{strings.Join(csvHeader, ",")}, rows...), "\n") + "\n",
+		},
+		{
+			format:    "tsv",
+			noHeaders: true,
+			want:      strings.Replace(strings.Join(rows, "\n"), ",", "\t", -1) + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			model := testModel()
+			model.Items[1].ResourceModels[0].Resources = []string{"*"}
+			model.Items[1].ResourceModels[0].Expanded = []string{"apps/v1/daemonsets", "apps/v1/deployments", "apps/v1/replicasets"}
+			out := renderModel(t, model, func(o *Options) {
+				o.Format = tt.format
+				o.NoHeaders = tt.noHeaders
+			})
+			if out != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// describer writes the "Key:  value" lines of the describe output,
+// indented by level and aligned the way kubectl describe does.
+type describer struct {
+	w            *tabwriter.Writer
+	now          time.Time
+	listExpanded bool
+}
+
+func (d *describer) line(level int, format string, args ...interface{}) {
+	fmt.Fprintf(d.w, strings.Repeat("  ", level)+format+"\n", args...)
+}
+
+// printDescribe prints every field of the configurations and webhooks of
+// the given PrintModel vertically, modelled on kubectl describe.
+func (p *Printer) printDescribe(model *PrintModel) error {
+	d := &describer{
+		w:            tabwriter.NewWriter(p.out, 0, 8, 2, ' ', 0),
+		now:          time.Now(),
+		listExpanded: p.opts.ListExpanded,
+	}
+
+	for i, item := range model.Items {
+		if i == 0 || !sameConfiguration(model.Items[i-1], item) {
+			if i > 0 {
+				d.line(0, "")
+			}
+			d.configuration(item)
+			if item.Policy == nil {
+				d.line(0, "Webhooks:")
+			}
+		}
+
+		if item.Policy != nil {
+			d.policy(item)
+		} else {
+			d.line(1, "%s:", item.Webhook.Name)
+			d.webhook(item, 2)
+		}
+	}
+	return d.w.Flush()
+}
+
+func sameConfiguration(a, b PrintItem) bool {
+	return a.Policy == nil && b.Policy == nil && a.Kind == b.Kind && a.Name == b.Name
+}
+
+// configurationKind returns the kind of the object the item was built from.
+func configurationKind(item PrintItem) string {
+	switch {
+	case item.Policy != nil && item.Kind == "Mutating":
+		return "MutatingAdmissionPolicy"
+	case item.Policy != nil:
+		return "ValidatingAdmissionPolicy"
+	}
+	return item.Kind + "WebhookConfiguration"
+}
+
+func (d *describer) configuration(item PrintItem) {
+	d.line(0, "Name:\t%s", item.Name)
+	d.line(0, "Kind:\t%s", configurationKind(item))
+
+	p := item.Provenance
+	if p == nil {
+		return
+	}
+	if !p.CreationTimestamp.IsZero() {
+		d.line(0, "Created:\t%s (%s ago)", p.CreationTimestamp.Format(time.RFC1123Z), d.age(p.CreationTimestamp))
+	}
+	if p.Generation != 0 {
+		d.line(0, "Generation:\t%d", p.Generation)
+	}
+	d.line(0, "Owner:\t%s", orNone(p.Owner))
+	if p.HelmRelease != "" {
+		d.line(0, "Helm Release:\t%s/%s", p.HelmNamespace, p.HelmRelease)
+	}
+	if p.ManagedBy != "" {
+		d.line(0, "Managed By:\t%s", p.ManagedBy)
+	}
+	d.line(0, "Managers:\t%s", orNone(strings.Join(p.Managers, ", ")))
+	d.line(0, "Owner References:\t%s", orNone(strings.Join(p.OwnerReferences, ", ")))
+	if p.InjectCAFrom != "" {
+		d.line(0, "Inject CA From:\t%s", p.InjectCAFrom)
+	}
+}
+
+func (d *describer) policy(item PrintItem) {
+	policy := item.Policy
+	d.line(0, "Param Kind:\t%s", orNone(policy.ParamKind))
+	if len(policy.Validations) > 0 {
+		d.line(0, "Validations:")
+		for _, v := range policy.Validations {
+			d.line(1, "Expression:\t%s", oneLine(v.Expression))
+			if v.Message != "" {
+				d.line(2, "Message:\t%s", v.Message)
+			}
+			if v.Reason != "" {
+				d.line(2, "Reason:\t%s", v.Reason)
+			}
+		}
+	}
+	if len(policy.Mutations) > 0 {
+		d.line(0, "Mutations:")
+		for _, m := range policy.Mutations {
+			d.line(1, "%s:\t%s", m.PatchType, oneLine(m.Expression))
+		}
+		d.line(0, "Reinvocation Policy:\t%s", orNone(policy.ReinvocationPolicy))
+	}
+	d.line(0, "Bindings:")
+	if len(policy.Bindings) == 0 {
+		d.line(1, "<none>")
+	}
+	for _, b := range policy.Bindings {
+		d.line(1, "%s:", b.Name)
+		if b.ParamRef != "" {
+			d.line(2, "Param Ref:\t%s", b.ParamRef)
+		}
+		if len(b.ValidationActions) > 0 {
+			d.line(2, "Validation Actions:\t%s", strings.Join(b.ValidationActions, ", "))
+		}
+		d.line(2, "Active Namespaces:\t%s", orNone(strings.Join(b.ActiveNamespaces, ", ")))
+		if b.NamespaceSelector != nil {
+			d.selector(2, "Namespace Selector", b.NamespaceSelector)
+		}
+		if b.ObjectSelector != nil {
+			d.selector(2, "Object Selector", b.ObjectSelector)
+		}
+	}
+	d.webhook(item, 0)
+}
+
+// webhook writes the fields webhooks and policies have in common, and the
+// client config of webhooks, at the given level.
+func (d *describer) webhook(item PrintItem, level int) {
+	d.line(level, "Failure Policy:\t%s", orNone(item.FailurePolicy))
+	d.line(level, "Match Policy:\t%s", orNone(item.MatchPolicy))
+	if item.Policy == nil {
+		d.line(level, "Side Effects:\t%s", orNone(item.SideEffects))
+		if item.TimeoutSeconds != nil {
+			d.line(level, "Timeout Seconds:\t%d", *item.TimeoutSeconds)
+		}
+		d.line(level, "Admission Review Versions:\t%s", orNone(strings.Join(item.AdmissionReviewVersions, ", ")))
+		if item.ReinvocationPolicy != "" {
+			d.line(level, "Reinvocation Policy:\t%s", item.ReinvocationPolicy)
+		}
+	}
+	d.selector(level, "Namespace Selector", item.NamespaceSelector)
+	d.selector(level, "Object Selector", item.ObjectSelector)
+	d.line(level, "Active Namespaces:\t%s", orNone(strings.Join(item.ActiveNamespaces, ", ")))
+
+	d.rules(level, "Rules", item.ResourceModels)
+	if len(item.ExcludedResourceModels) > 0 {
+		d.rules(level, "Excluded Rules", item.ExcludedResourceModels)
+	}
+	if len(item.MatchConditions) > 0 {
+		d.line(level, "Match Conditions:")
+		for _, mc := range item.MatchConditions {
+			d.line(level+1, "%s:\t%s", mc.Name, oneLine(mc.Expression))
+			if mc.Error != "" {
+				d.line(level+2, "Error:\t%s", mc.Error)
+			}
+		}
+	}
+
+	if item.Policy == nil {
+		d.clientConfig(level, item)
+		d.caBundle(level, item)
+	}
+	d.findings(level, item.Findings)
+	d.failures(level, item.Failures)
+	d.events(level, item.Events)
+}
+
+func (d *describer) failures(level int, failures *PrintFailuresItem) {
+	if failures == nil {
+		return
+	}
+	d.line(level, "Failures:")
+	d.line(level+1, "Failed:\t%d", failures.Failed)
+	d.line(level+1, "Denied:\t%d", failures.Denied)
+	d.line(level+1, "Last Seen:\t%s ago", d.age(failures.LastSeen))
+	for _, e := range failures.Recent {
+		d.line(level+1, "Recent:\t%s %s: %s", e.Object, e.Reason, oneLine(e.Message))
+	}
+}
+
+func (d *describer) selector(level int, name string, selector *metaV1.LabelSelector) {
+	if selector == nil {
+		d.line(level, "%s:\t<none>", name)
+		return
+	}
+	if len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 {
+		d.line(level, "%s:\t<all>", name)
+		return
+	}
+	d.line(level, "%s:", name)
+	if len(selector.MatchLabels) > 0 {
+		d.line(level+1, "Match Labels:\t%s", metaV1.FormatLabelSelector(&metaV1.LabelSelector{MatchLabels: selector.MatchLabels}))
+	}
+	for _, e := range selector.MatchExpressions {
+		d.line(level+1, "Match Expression:\t%s %s [%s]", e.Key, e.Operator, strings.Join(e.Values, ", "))
+	}
+}
+
+func (d *describer) rules(level int, title string, rules []ResourceModel) {
+	if len(rules) == 0 {
+		d.line(level, "%s:\t<none>", title)
+		return
+	}
+	d.line(level, "%s:", title)
+	d.line(level+1, "Operations\tAPI Groups\tAPI Versions\tResources\tScope")
+	d.line(level+1, "----------\t----------\t------------\t---------\t-----")
+	for _, rm := range rules {
+		resources := strings.Join(rm.Resources, ", ")
+		if len(rm.ResourceNames) > 0 {
+			resources += " (" + strings.Join(rm.ResourceNames, ", ") + ")"
+		}
+		d.line(level+1, "%s\t%s\t%s\t%s\t%s", strings.Join(rm.Operations, ", "), strings.Join(describeGroups(rm.APIGroups), ", "),
+			strings.Join(rm.APIVersions, ", "), resources, orNone(rm.Scope))
+	}
+
+	for _, rm := range rules {
+		for _, r := range rm.Unserved {
+			d.line(level+1, "Not Served:\t%s", r)
+		}
+		for _, r := range rm.Unknown {
+			d.line(level+1, "Not Discovered:\t%s", r)
+		}
+		if rm.Expanded == nil {
+			continue
+		}
+		d.line(level+1, "Expanded:\t%s matches %d served resources", strings.Join(rm.Resources, ", "), len(rm.Expanded))
+		if d.listExpanded {
+			for _, r := range rm.Expanded {
+				d.line(level+2, "%s", r)
+			}
+		}
+	}
+}
+
+// describeGroups names the core group, which would otherwise be empty.
+func describeGroups(groups []string) []string {
+	var result []string
+	for _, g := range groups {
+		if g == "" {
+			g = `""`
+		}
+		result = append(result, g)
+	}
+	return result
+}
+
+func (d *describer) clientConfig(level int, item PrintItem) {
+	d.line(level, "Client Config:")
+	if item.Webhook.URL != nil {
+		d.line(level+1, "URL:\t%s", *item.Webhook.URL)
+	}
+
+	service := item.Webhook.Service
+	if service.Name == "" {
+		return
+	}
+	d.line(level+1, "Service:\t%s/%s", service.Namespace, service.Name)
+	if service.Port != nil {
+		d.line(level+1, "Port:\t%d", *service.Port)
+	}
+	if service.Path != nil {
+		d.line(level+1, "Path:\t%s", *service.Path)
+	}
+	if !service.Found {
+		d.line(level+1, "Status:\tservice not found")
+		return
+	}
+
+	d.line(level+1, "Type:\t%s", service.Type)
+	d.line(level+1, "IP:\t%s", service.ClusterIP)
+	for _, port := range service.Ports {
+		d.line(level+1, "Port Mapping:\t%d -> %d/%s", port.Port, port.TargetPort, port.Protocol)
+	}
+	if len(service.Selector) > 0 {
+		d.line(level+1, "Selector:\t%s", metaV1.FormatLabelSelector(&metaV1.LabelSelector{MatchLabels: service.Selector}))
+	}
+
+	if len(service.Endpoints) == 0 {
+		d.line(level+1, "Endpoints:\t<none>")
+	} else {
+		d.line(level+1, "Endpoints:")
+		for _, ep := range service.Endpoints {
+			ready := "Ready"
+			if !ep.Ready {
+				ready = "NotReady"
+			}
+			d.line(level+2, "%s\t%s\t%s", ep.IP, orNone(ep.Pod), ready)
+		}
+	}
+
+	for _, s := range service.ServingSecrets {
+		d.line(level+1, "Serving Secret:\t%s (pod %s)", s.Name, s.Pod)
+		if s.Verified {
+			d.line(level+2, "Verified:\ttrue")
+		} else {
+			d.line(level+2, "Verified:\tfalse, %s", s.VerifyError)
+		}
+		if !s.NotAfter.IsZero() {
+			d.line(level+2, "Not After:\t%s", s.NotAfter.Format(time.RFC1123Z))
+		}
+		if len(s.DNSNames) > 0 {
+			d.line(level+2, "DNS Names:\t%s", strings.Join(s.DNSNames, ", "))
+		}
+	}
+
+	if probe := item.Webhook.Probe; probe != nil {
+		d.line(level+1, "Probe:")
+		d.line(level+2, "Target:\t%s via %s", probe.Target, probe.Via)
+		if probe.Error != "" {
+			d.line(level+2, "Error:\t%s", probe.Error)
+			return
+		}
+		d.line(level+2, "Latency:\t%s", probe.Latency)
+		if probe.Verified {
+			d.line(level+2, "Verified:\ttrue")
+		} else {
+			d.line(level+2, "Verified:\tfalse, %s", probe.VerifyError)
+		}
+	}
+}
+
+func (d *describer) caBundle(level int, item PrintItem) {
+	if item.CABundleFingerprint == "" {
+		d.line(level, "CA Bundle:\t<none>")
+		return
+	}
+	d.line(level, "CA Bundle:")
+	d.line(level+1, "Fingerprint:\t%s", item.CABundleFingerprint)
+	for _, cert := range item.CABundleCertificates {
+		d.line(level+1, "Certificate:")
+		d.line(level+2, "Subject:\t%s", cert.Subject)
+		d.line(level+2, "Issuer:\t%s", cert.Issuer)
+		d.line(level+2, "Serial Number:\t%s", cert.SerialNumber)
+		d.line(level+2, "Not Before:\t%s", cert.NotBefore.Format(time.RFC1123Z))
+		d.line(level+2, "Not After:\t%s (%s)", cert.NotAfter.Format(time.RFC1123Z), d.expiry(cert.NotAfter))
+		if len(cert.DNSNames) > 0 {
+			d.line(level+2, "DNS Names:\t%s", strings.Join(cert.DNSNames, ", "))
+		}
+		d.line(level+2, "CA:\t%t", cert.IsCA)
+	}
+
+	if ca := item.CAInjection; ca != nil {
+		d.line(level+1, "CA Injection:\t%s", ca.Status)
+		if ca.Certificate != "" {
+			d.line(level+2, "Certificate:\t%s", ca.Certificate)
+		}
+		if ca.Secret != "" {
+			d.line(level+2, "Secret:\t%s", ca.Secret)
+		}
+		if ca.Error != "" {
+			d.line(level+2, "Error:\t%s", ca.Error)
+		}
+	}
+}
+
+func (d *describer) findings(level int, findings []Finding) {
+	if len(findings) == 0 {
+		d.line(level, "Findings:\t<none>")
+		return
+	}
+	d.line(level, "Findings:")
+	d.line(level+1, "Severity\tCheck\tMessage")
+	d.line(level+1, "--------\t-----\t-------")
+	for _, f := range findings {
+		d.line(level+1, "%s\t%s\t%s", f.Severity, f.Check, f.Message)
+	}
+}
+
+func (d *describer) events(level int, events []PrintEventItem) {
+	if len(events) == 0 {
+		d.line(level, "Events:\t<none>")
+		return
+	}
+	d.line(level, "Events:")
+	d.line(level+1, "Type\tReason\tAge\tObject\tMessage")
+	d.line(level+1, "----\t------\t---\t------\t-------")
+	for _, e := range events {
+		age := d.age(e.LastSeen)
+		if e.Count > 1 {
+			age = fmt.Sprintf("%s (x%d)", age, e.Count)
+		}
+		d.line(level+1, "%s\t%s\t%s\t%s\t%s", e.Type, e.Reason, age, e.Object, oneLine(e.Message))
+	}
+}
+
+func (d *describer) age(t time.Time) string {
+	return duration.HumanDuration(d.now.Sub(t))
+}
+
+// expiry tells how long until, or since, the given time.
+func (d *describer) expiry(t time.Time) string {
+	if t.Before(d.now) {
+		return "expired " + d.age(t) + " ago"
+	}
+	return "expires in " + duration.HumanDuration(t.Sub(d.now))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
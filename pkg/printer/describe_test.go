@@ -0,0 +1,89 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrintDescribe(t *testing.T) {
+	model := testModel()
+	second := model.Items[0]
+	second.Webhook.Name = "second.injector.io"
+	second.Findings = nil
+	model.Items = append(model.Items[:1], second, model.Items[1])
+	model.Items[0].CABundleFingerprint = "aa:bb"
+	model.Items[0].CABundleCertificates = []PrintCertificateItem{{
+		Subject:      "CN=injector.mesh.svc",
+		Issuer:       "CN=mesh-ca",
+		SerialNumber: "42",
+		NotAfter:     time.Now().Add(400*24*time.Hour + time.Hour),
+		DNSNames:     []string{"injector.mesh.svc"},
+	}}
+	model.Items[0].Events = []PrintEventItem{{
+		Type:     "Warning",
+		Reason:   "FailedCreate",
+		Object:   "Pod/injector-0",
+		Count:    3,
+		LastSeen: time.Now().Add(-5 * time.Minute),
+		Message:  "context deadline\nexceeded",
+	}}
+	model.Items[0].Failures = &PrintFailuresItem{
+		Failed:   4,
+		Denied:   1,
+		LastSeen: time.Now().Add(-10 * time.Minute),
+		Recent:   []PrintEventItem{{Object: "ReplicaSet/web", Reason: "FailedCreate", Message: `failed calling webhook "sidecar.injector.io": EOF`}},
+	}
+	model.Items = append(model.Items, PrintItem{
+		Kind:          "Validating",
+		Name:          "replicas",
+		FailurePolicy: "Fail",
+		Policy: &PrintPolicyItem{
+			Validations: []PrintValidationItem{{Expression: "object.spec.replicas <= 5", Message: "too many replicas"}},
+			Bindings:    []PrintPolicyBindingItem{{Name: "replicas-prod", ValidationActions: []string{"Deny", "Audit"}, ActiveNamespaces: []string{"payments"}}},
+		},
+	})
+
+	out := renderModel(t, model, func(o *Options) { o.Format = "describe" })
+
+	if n := strings.Count(out, "Kind:  MutatingWebhookConfiguration"); n != 1 {
+		t.Errorf("got the mutating configuration %d times, want its webhooks grouped once:\n%s", n, out)
+	}
+	for _, want := range []string{
+		"  sidecar.injector.io:\n    Failure Policy:             Fail\n",
+		"  second.injector.io:\n",
+		"      CREATE, UPDATE  \"\"          v1            pods       Namespaced\n",
+		"      Port Mapping:  443 -> 8443/TCP\n",
+		"      Subject:        CN=injector.mesh.svc\n",
+		"      Not After:      ",
+		"(expires in 400d)\n",
+		"      critical  self-interception  blocks its own recovery\n",
+		"    Failures:\n      Failed:     4\n      Denied:     1\n      Last Seen:  10m ago\n",
+		"      Recent:     ReplicaSet/web FailedCreate: failed calling webhook \"sidecar.injector.io\": EOF\n",
+		"      Warning  FailedCreate  5m (x3)  Pod/injector-0  context deadline exceeded\n",
+		"Kind:  ValidatingWebhookConfiguration\nWebhooks:\n  deny.all.io:\n",
+		"Name:        replicas\nKind:        ValidatingAdmissionPolicy\n",
+		"  Expression:  object.spec.replicas <= 5\n    Message:   too many replicas\n",
+		"  replicas-prod:\n    Validation Actions:  Deny, Audit\n    Active Namespaces:   payments\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("describe does not contain %q:\n%s", want, out)
+		}
+	}
+}
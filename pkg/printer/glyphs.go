@@ -0,0 +1,66 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"github.com/pterm/pterm"
+	"golang.org/x/term"
+	"io"
+	"os"
+)
+
+// glyphs are the symbols the table output is drawn with.
+type glyphs struct {
+	Cross  string
+	Check  string
+	Bullet string
+	// Ellipsis ends truncated text.
+	Ellipsis string
+	Tree     pterm.TreePrinter
+}
+
+var unicodeGlyphs = glyphs{
+	Cross:    "✖",
+	Check:    "✔",
+	Bullet:   pterm.DefaultBulletList.Bullet,
+	Ellipsis: "…",
+	Tree:     pterm.DefaultTree,
+}
+
+// asciiGlyphs keeps logs readable where unicode is not rendered.
+var asciiGlyphs = glyphs{
+	Cross:    "x",
+	Check:    "v",
+	Bullet:   "*",
+	Ellipsis: "...",
+	Tree: pterm.TreePrinter{
+		TreeStyle:            pterm.DefaultTree.TreeStyle,
+		TextStyle:            pterm.DefaultTree.TextStyle,
+		TopRightCornerString: "`",
+		HorizontalString:     "-",
+		TopRightDownString:   "|",
+		VerticalString:       "|",
+		RightDownLeftString:  "+",
+		Indent:               pterm.DefaultTree.Indent,
+	},
+}
+
+// isTerminal reports whether the given writer is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,205 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"strings"
+)
+
+type graphNodeKind string
+
+const (
+	graphConfiguration graphNodeKind = "configuration"
+	graphWebhook       graphNodeKind = "webhook"
+	graphService       graphNodeKind = "service"
+	graphURL           graphNodeKind = "url"
+	graphPod           graphNodeKind = "pod"
+	graphRule          graphNodeKind = "rule"
+	graphNamespace     graphNodeKind = "namespace"
+)
+
+type graphNode struct {
+	ID    string
+	Kind  graphNodeKind
+	Label []string
+}
+
+type graphEdge struct {
+	From, To string
+}
+
+// graph is the admission topology: configurations point to their
+// webhooks, webhooks to their services or urls and services to their
+// pods, while rules and namespaces point into the webhooks they select.
+type graph struct {
+	nodes []graphNode
+	edges []graphEdge
+	index map[string]int
+	seen  map[graphEdge]bool
+}
+
+func newGraph(model *PrintModel) *graph {
+	g := &graph{
+		index: map[string]int{},
+		seen:  map[graphEdge]bool{},
+	}
+
+	for _, item := range model.Items {
+		cfg := g.node(graphConfiguration, item.Kind+"/"+item.Name, item.Kind, item.Name)
+		wh := g.node(graphWebhook, item.Kind+"/"+item.Name+"/"+item.Webhook.Name, item.Webhook.Name)
+		g.edge(cfg, wh)
+
+		service := item.Webhook.Service
+		switch {
+		case service.Name != "":
+			svc := g.node(graphService, service.Namespace+"/"+service.Name, service.Name, service.Namespace)
+			g.edge(wh, svc)
+			for _, ep := range service.Endpoints {
+				name := ep.Pod
+				if name == "" {
+					name = ep.IP
+				}
+				g.edge(svc, g.node(graphPod, service.Namespace+"/"+name, name, service.Namespace))
+			}
+		case item.Webhook.URL != nil:
+			g.edge(wh, g.node(graphURL, *item.Webhook.URL, *item.Webhook.URL))
+		}
+
+		for _, rm := range item.ResourceModels {
+			groupVersions := ruleGroups(rm.APIGroups, ",") + "/" + strings.Join(rm.APIVersions, ",")
+			resources := strings.Join(rm.Resources, ",")
+			if len(rm.ResourceNames) > 0 {
+				resources += "[" + strings.Join(rm.ResourceNames, ",") + "]"
+			}
+			operations := strings.Join(rm.Operations, ",")
+			key := strings.Join([]string{groupVersions, resources, operations, rm.Scope}, ":")
+			g.edge(g.node(graphRule, key, groupVersions, resources, operations), wh)
+		}
+
+		// webhooks selecting every namespace point to a single node rather
+		// than one per namespace, policies are only bound to the namespaces
+		// their bindings select so theirs are always drawn
+		if item.Policy == nil && SelectsEverything(item.NamespaceSelector) {
+			g.edge(g.node(graphNamespace, "*", "all namespaces"), wh)
+			continue
+		}
+		for _, ns := range item.ActiveNamespaces {
+			g.edge(g.node(graphNamespace, ns, ns), wh)
+		}
+	}
+
+	return g
+}
+
+// ruleGroups joins the API groups of a rule with the given separator,
+// naming the core group, which would otherwise be an empty value.
+func ruleGroups(groups []string, sep string) string {
+	var names []string
+	for _, group := range groups {
+		if group == "" {
+			group = "core"
+		}
+		names = append(names, group)
+	}
+	return strings.Join(names, sep)
+}
+
+// node returns the id of the node of the given kind and key, adding it on
+// first use.
+func (g *graph) node(kind graphNodeKind, key string, label ...string) string {
+	key = string(kind) + ":" + key
+	if i, ok := g.index[key]; ok {
+		return g.nodes[i].ID
+	}
+	id := fmt.Sprintf("n%d", len(g.nodes))
+	g.index[key] = len(g.nodes)
+	g.nodes = append(g.nodes, graphNode{ID: id, Kind: kind, Label: label})
+	return id
+}
+
+func (g *graph) edge(from, to string) {
+	e := graphEdge{From: from, To: to}
+	if g.seen[e] {
+		return
+	}
+	g.seen[e] = true
+	g.edges = append(g.edges, e)
+}
+
+var dotShapes = map[graphNodeKind]string{
+	graphConfiguration: "folder",
+	graphWebhook:       "box",
+	graphService:       "component",
+	graphURL:           "component",
+	graphPod:           "ellipse",
+	graphRule:          "note",
+	graphNamespace:     "tab",
+}
+
+// printDOT prints the admission topology of the given PrintModel in the
+// Graphviz DOT language.
+func (p *Printer) printDOT(model *PrintModel) error {
+	g := newGraph(model)
+
+	var b strings.Builder
+	b.WriteString("digraph admission {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	for _, n := range g.nodes {
+		label := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(strings.Join(n.Label, "\n"))
+		label = strings.Replace(label, "\n", `\n`, -1)
+		fmt.Fprintf(&b, "  %s [label=\"%s\", shape=%s];\n", n.ID, label, dotShapes[n.Kind])
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "  %s -> %s;\n", e.From, e.To)
+	}
+	b.WriteString("}\n")
+
+	_, err := fmt.Fprint(p.out, b.String())
+	return err
+}
+
+var mermaidShapes = map[graphNodeKind][2]string{
+	graphConfiguration: {"[[", "]]"},
+	graphWebhook:       {"[", "]"},
+	graphService:       {"[(", ")]"},
+	graphURL:           {"[(", ")]"},
+	graphPod:           {"([", "])"},
+	graphRule:          {">", "]"},
+	graphNamespace:     {"{{", "}}"},
+}
+
+// printMermaid prints the admission topology of the given PrintModel as a
+// Mermaid flowchart.
+func (p *Printer) printMermaid(model *PrintModel) error {
+	g := newGraph(model)
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, n := range g.nodes {
+		label := strings.Replace(strings.Join(n.Label, "<br/>"), `"`, "#quot;", -1)
+		shape := mermaidShapes[n.Kind]
+		fmt.Fprintf(&b, "  %s%s\"%s\"%s\n", n.ID, shape[0], label, shape[1])
+	}
+	for _, e := range g.edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", e.From, e.To)
+	}
+
+	_, err := fmt.Fprint(p.out, b.String())
+	return err
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+// graphModel returns two webhooks of one configuration sharing their
+// rules, one served by a pod in the namespaces it selects and one by a url
+// in every namespace.
+func graphModel() *PrintModel {
+	url := "https://audit.example.com/admit"
+	rules := []ResourceModel{{
+		APIGroups:   []string{""},
+		APIVersions: []string{"v1"},
+		Operations:  []string{"CREATE"},
+		Resources:   []string{"pods"},
+	}}
+	return &PrintModel{Items: []PrintItem{
+		{
+			Name: "policy",
+			Kind: "Validating",
+			Webhook: PrintWebhookItem{
+				Name: "pods.policy.io",
+				Service: PrintServiceItem{
+					Name:      "policy",
+					Namespace: "policy",
+					Endpoints: []PrintEndpointItem{{IP: "10.0.0.1", Pod: "policy-0"}},
+				},
+			},
+			ResourceModels:    rules,
+			NamespaceSelector: &metaV1.LabelSelector{MatchLabels: map[string]string{"policy": "enabled"}},
+			ActiveNamespaces:  []string{"default"},
+		},
+		{
+			Name:             "policy",
+			Kind:             "Validating",
+			Webhook:          PrintWebhookItem{Name: "audit.policy.io", URL: &url},
+			ResourceModels:   rules,
+			ActiveNamespaces: []string{"default"},
+		},
+	}}
+}
+
+func TestPrintGraph(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "dot",
+			want: `digraph admission {
+  rankdir=LR;
+  node [fontname="Helvetica"];
+  n0 [label="Validating\npolicy", shape=folder];
+  n1 [label="pods.policy.io", shape=box];
+  n2 [label="policy\npolicy", shape=component];
+  n3 [label="policy-0\npolicy", shape=ellipse];
+  n4 [label="core/v1\npods\nCREATE", shape=note];
+  n5 [label="default", shape=tab];
+  n6 [label="audit.policy.io", shape=box];
+  n7 [label="https://audit.example.com/admit", shape=component];
+  n8 [label="all namespaces", shape=tab];
+  n0 -> n1;
+  n1 -> n2;
+  n2 -> n3;
+  n4 -> n1;
+  n5 -> n1;
+  n0 -> n6;
+  n6 -> n7;
+  n4 -> n6;
+  n8 -> n6;
+}
+`,
+		},
+		{
+			format: "mermaid",
+			want: `graph LR
+  n0[["Validating<br/>policy"]]
+  n1["pods.policy.io"]
+  n2[("policy<br/>policy")]
+  n3(["policy-0<br/>policy"])
+  n4>"core/v1<br/>pods<br/>CREATE"]
+  n5{{"default"}}
+  n6["audit.policy.io"]
+  n7[("https://audit.example.com/admit")]
+  n8{{"all namespaces"}}
+  n0 --> n1
+  n1 --> n2
+  n2 --> n3
+  n4 --> n1
+  n5 --> n1
+  n0 --> n6
+  n6 --> n7
+  n4 --> n6
+  n8 --> n6
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out := renderModel(t, graphModel(), func(o *Options) { o.Format = tt.format })
+			if out != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", out, tt.want)
+			}
+		})
+	}
+}
@@ -16,43 +16,265 @@ limitations under the License.
 
 package printer
 
-import "time"
+import (
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
 
 type PrintModel struct {
-	Items []PrintItem
+	Items []PrintItem `json:"items"`
 }
 
 type ResourceModel struct {
-	Operations []string
-	Resources  []string
+	APIGroups   []string `json:"apiGroups,omitempty"`
+	APIVersions []string `json:"apiVersions,omitempty"`
+	Operations  []string `json:"operations"`
+	Resources   []string `json:"resources"`
+	// ResourceNames restricts the rule to the named objects, only
+	// admission policies can do so.
+	ResourceNames []string `json:"resourceNames,omitempty"`
+	Scope         string   `json:"scope,omitempty"`
+	// Expanded are the served resources a rule with wildcards matches, as
+	// group/version/resource, nil unless the cluster could be discovered.
+	Expanded []string `json:"expanded,omitempty"`
+	// Unserved are the group/version/resource combinations of a rule the
+	// cluster does not serve, so it never fires for them.
+	Unserved []string `json:"unserved,omitempty"`
+	// Unknown are the group/version/resource combinations of a rule in
+	// group versions the cluster failed to discover, which may be served.
+	Unknown []string `json:"unknown,omitempty"`
 }
 
 type PrintItem struct {
-	Name             string
-	Webhook          PrintWebhookItem
-	Kind             string
-	ResourceModels   []ResourceModel
-	ValidUntil       time.Duration
-	ActiveNamespaces []string
+	Name                string           `json:"name"`
+	Webhook             PrintWebhookItem `json:"webhook"`
+	Kind                string           `json:"kind"`
+	ResourceModels      []ResourceModel  `json:"resourceModels"`
+	ValidUntil          time.Duration    `json:"validUntil"`
+	CABundleFingerprint string           `json:"caBundleFingerprint,omitempty"`
+	CABundleIssuer      string           `json:"caBundleIssuer,omitempty"`
+	// CABundleCertificates are the decoded certificates of the CABundle.
+	CABundleCertificates []PrintCertificateItem `json:"caBundleCertificates,omitempty"`
+	ActiveNamespaces     []string               `json:"activeNamespaces"`
+	FailurePolicy        string                 `json:"failurePolicy,omitempty"`
+	TimeoutSeconds       *int32                 `json:"timeoutSeconds,omitempty"`
+	NamespaceSelector    *metaV1.LabelSelector  `json:"namespaceSelector,omitempty"`
+	ObjectSelector       *metaV1.LabelSelector  `json:"objectSelector,omitempty"`
+	// MatchPolicy is Exact or Equivalent, with Equivalent the rules also
+	// match other versions and groups of the same resource.
+	MatchPolicy             string   `json:"matchPolicy,omitempty"`
+	SideEffects             string   `json:"sideEffects,omitempty"`
+	AdmissionReviewVersions []string `json:"admissionReviewVersions,omitempty"`
+	// ReinvocationPolicy is only set on mutating webhooks.
+	ReinvocationPolicy string                    `json:"reinvocationPolicy,omitempty"`
+	MatchConditions    []PrintMatchConditionItem `json:"matchConditions,omitempty"`
+	Findings           []Finding                 `json:"findings,omitempty"`
+	Provenance         *PrintProvenanceItem      `json:"provenance,omitempty"`
+	CAInjection        *PrintCAInjectionItem     `json:"caInjection,omitempty"`
+	// Policy is set on items of kind Policy, admission policies evaluated
+	// by the API server itself rather than by a webhook.
+	Policy *PrintPolicyItem `json:"policy,omitempty"`
+	// ExcludedResourceModels are the excluded rules of a policy that
+	// overlap its ResourceModels without narrowing them down.
+	ExcludedResourceModels []ResourceModel `json:"excludedResourceModels,omitempty"`
+	// Events are the recent events about the configuration, its service
+	// and the service's pods, only fetched when asked for.
+	Events []PrintEventItem `json:"events,omitempty"`
+	// Failures sums up the recent admission requests the webhook failed or
+	// denied, as told by the events of the objects they were made for.
+	Failures *PrintFailuresItem `json:"failures,omitempty"`
 }
 
 type PrintWebhookItem struct {
-	Name    string
-	Service PrintServiceItem
+	Name    string           `json:"name"`
+	URL     *string          `json:"url,omitempty"`
+	Service PrintServiceItem `json:"service"`
+	Probe   *PrintProbeItem  `json:"probe,omitempty"`
 }
 
 type PrintServiceItem struct {
-	Found     bool
-	Name      string
-	Namespace string
-	Path      *string
-	Ports     []PrintServicePortItem
-	ClusterIP string
-	Type      string
+	Found     bool                   `json:"found"`
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Path      *string                `json:"path,omitempty"`
+	Port      *int32                 `json:"port,omitempty"`
+	Ports     []PrintServicePortItem `json:"ports,omitempty"`
+	ClusterIP string                 `json:"clusterIP,omitempty"`
+	Type      string                 `json:"type,omitempty"`
+	Selector  map[string]string      `json:"selector,omitempty"`
+	Endpoints []PrintEndpointItem    `json:"endpoints,omitempty"`
+	// ServingSecrets are the TLS secrets mounted by the service's pods.
+	ServingSecrets []PrintServingSecretItem `json:"servingSecrets,omitempty"`
+}
+
+type PrintEndpointItem struct {
+	IP    string `json:"ip"`
+	Pod   string `json:"pod,omitempty"`
+	Ready bool   `json:"ready"`
+}
+
+// PrintServingSecretItem is a TLS secret mounted by a webhook's pods,
+// verified against the webhook's CABundle and service DNS name.
+type PrintServingSecretItem struct {
+	Name        string    `json:"name"`
+	Pod         string    `json:"pod"`
+	DNSNames    []string  `json:"dnsNames,omitempty"`
+	NotAfter    time.Time `json:"notAfter,omitempty"`
+	Verified    bool      `json:"verified"`
+	VerifyError string    `json:"verifyError,omitempty"`
 }
 
 type PrintServicePortItem struct {
-	Port       int32
-	TargetPort int32
-	Protocol   string
+	Port       int32  `json:"port"`
+	TargetPort int32  `json:"targetPort,omitempty"`
+	Protocol   string `json:"protocol"`
+}
+
+type PrintProbeItem struct {
+	Via              string        `json:"via,omitempty"`
+	Target           string        `json:"target,omitempty"`
+	ServerName       string        `json:"serverName,omitempty"`
+	Latency          time.Duration `json:"latency,omitempty"`
+	Verified         bool          `json:"verified"`
+	VerifyError      string        `json:"verifyError,omitempty"`
+	Error            string        `json:"error,omitempty"`
+	ServedNotAfter   time.Time     `json:"servedNotAfter,omitempty"`
+	CABundleNotAfter time.Time     `json:"caBundleNotAfter,omitempty"`
+}
+
+// PrintCertificateItem is a decoded X.509 certificate.
+type PrintCertificateItem struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serialNumber"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+	DNSNames     []string  `json:"dnsNames,omitempty"`
+	IsCA         bool      `json:"isCA"`
+}
+
+// PrintEventItem is a Kubernetes event related to a webhook.
+type PrintEventItem struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Object   string    `json:"object"`
+	Count    int32     `json:"count"`
+	LastSeen time.Time `json:"lastSeen"`
+	Message  string    `json:"message"`
+}
+
+// PrintFailuresItem counts the events of admission requests a webhook
+// failed to be called for or denied.
+type PrintFailuresItem struct {
+	Failed   int32     `json:"failed"`
+	Denied   int32     `json:"denied"`
+	LastSeen time.Time `json:"lastSeen"`
+	// Recent are the most recent of these events, newest first.
+	Recent []PrintEventItem `json:"recent"`
+}
+
+// PrintProvenanceItem tells who created and manages a configuration.
+type PrintProvenanceItem struct {
+	CreationTimestamp time.Time `json:"creationTimestamp"`
+	Generation        int64     `json:"generation,omitempty"`
+	Managers          []string  `json:"managers,omitempty"`
+	OwnerReferences   []string  `json:"ownerReferences,omitempty"`
+	HelmRelease       string    `json:"helmRelease,omitempty"`
+	HelmNamespace     string    `json:"helmNamespace,omitempty"`
+	ManagedBy         string    `json:"managedBy,omitempty"`
+	InjectCAFrom      string    `json:"injectCAFrom,omitempty"`
+	// Owner is the most specific hint of who to contact, empty if unknown.
+	Owner string `json:"owner,omitempty"`
+}
+
+// PrintPolicyItem describes a CEL based admission policy.
+type PrintPolicyItem struct {
+	ParamKind          string                   `json:"paramKind,omitempty"`
+	Validations        []PrintValidationItem    `json:"validations,omitempty"`
+	Mutations          []PrintMutationItem      `json:"mutations,omitempty"`
+	ReinvocationPolicy string                   `json:"reinvocationPolicy,omitempty"`
+	Bindings           []PrintPolicyBindingItem `json:"bindings,omitempty"`
+}
+
+type PrintValidationItem struct {
+	Expression string `json:"expression"`
+	Message    string `json:"message,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// PrintMutationItem is a CEL expression producing an apply configuration
+// or a JSON patch.
+type PrintMutationItem struct {
+	PatchType  string `json:"patchType"`
+	Expression string `json:"expression"`
+}
+
+// PrintPolicyBindingItem is a binding applying a policy to the namespaces
+// it selects.
+type PrintPolicyBindingItem struct {
+	Name              string   `json:"name"`
+	ParamRef          string   `json:"paramRef,omitempty"`
+	ValidationActions []string `json:"validationActions,omitempty"`
+	ActiveNamespaces  []string `json:"activeNamespaces"`
+	// ResourceModels are the rules of the policy the binding applies,
+	// narrowed down by its own resourceRules and excludeResourceRules.
+	ResourceModels         []ResourceModel       `json:"resourceModels,omitempty"`
+	ExcludedResourceModels []ResourceModel       `json:"excludedResourceModels,omitempty"`
+	NamespaceSelector      *metaV1.LabelSelector `json:"namespaceSelector,omitempty"`
+	ObjectSelector         *metaV1.LabelSelector `json:"objectSelector,omitempty"`
+}
+
+// PrintMatchConditionItem is a CEL expression a request must satisfy to
+// be admitted by a webhook or policy, Error is set when it does not compile.
+type PrintMatchConditionItem struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+	Error      string `json:"error,omitempty"`
+}
+
+type CAInjectionStatus string
+
+const (
+	CAInjectionInSync             CAInjectionStatus = "in-sync"
+	CAInjectionMismatch           CAInjectionStatus = "mismatch"
+	CAInjectionCertificateMissing CAInjectionStatus = "certificate-missing"
+	CAInjectionSecretMissing      CAInjectionStatus = "secret-missing"
+	CAInjectionError              CAInjectionStatus = "error"
+	// CAInjectionUnchecked is the status of an injection whose Secret is
+	// not read, so the CABundle cannot be compared with it.
+	CAInjectionUnchecked CAInjectionStatus = "unchecked"
+)
+
+// PrintCAInjectionItem compares the CABundle of a webhook with the CA
+// cert-manager injects into it.
+type PrintCAInjectionItem struct {
+	Certificate    string            `json:"certificate,omitempty"`
+	Secret         string            `json:"secret,omitempty"`
+	Status         CAInjectionStatus `json:"status"`
+	Ready          bool              `json:"ready"`
+	Renewing       bool              `json:"renewing,omitempty"`
+	RenewalTime    *time.Time        `json:"renewalTime,omitempty"`
+	SecretNotAfter time.Time         `json:"secretNotAfter,omitempty"`
+	Error          string            `json:"error,omitempty"`
+}
+
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityWarning  Severity = "warning"
+	SeverityInfo     Severity = "info"
+)
+
+// Finding is a problem a lint check found on a webhook.
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// SelectsEverything tells whether the given namespace or object selector
+// matches every object, as an unset or empty selector does.
+func SelectsEverything(selector *metaV1.LabelSelector) bool {
+	return selector == nil || len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0
 }
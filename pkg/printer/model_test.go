@@ -0,0 +1,45 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+func TestSelectsEverything(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *metaV1.LabelSelector
+		want     bool
+	}{
+		{name: "unset", want: true},
+		{name: "empty", selector: &metaV1.LabelSelector{}, want: true},
+		{name: "labels", selector: &metaV1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}},
+		{name: "expressions", selector: &metaV1.LabelSelector{MatchExpressions: []metaV1.LabelSelectorRequirement{
+			{Key: "env", Operator: metaV1.LabelSelectorOpExists},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SelectsEverything(tt.selector); got != tt.want {
+				t.Errorf("SelectsEverything() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
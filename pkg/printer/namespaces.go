@@ -0,0 +1,197 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pterm/pterm"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// PrintNamespaceModel is the PrintModel inverted by namespace: every
+// webhook and policy intercepting requests in each namespace.
+type PrintNamespaceModel struct {
+	Items []PrintNamespaceItem `json:"items"`
+}
+
+type PrintNamespaceItem struct {
+	Namespace string                      `json:"namespace"`
+	Webhooks  []PrintNamespaceWebhookItem `json:"webhooks"`
+}
+
+type PrintNamespaceWebhookItem struct {
+	Kind           string          `json:"kind"`
+	Name           string          `json:"name"`
+	Webhook        string          `json:"webhook"`
+	FailurePolicy  string          `json:"failurePolicy,omitempty"`
+	ResourceModels []ResourceModel `json:"resourceModels"`
+}
+
+// NewNamespaceModel inverts the given model for the given namespaces. Only
+// the rules on namespaced resources are kept, rules on cluster scoped ones
+// apply regardless of namespace selectors.
+func NewNamespaceModel(model *PrintModel, namespaces []string) *PrintNamespaceModel {
+	result := &PrintNamespaceModel{}
+	for _, ns := range namespaces {
+		nsItem := PrintNamespaceItem{Namespace: ns, Webhooks: []PrintNamespaceWebhookItem{}}
+		for _, item := range model.Items {
+			if !containsString(item.ActiveNamespaces, ns) {
+				continue
+			}
+
+			var rules []ResourceModel
+			for _, rm := range item.ResourceModels {
+				if rm.Scope != "Cluster" {
+					rules = append(rules, rm)
+				}
+			}
+			if len(rules) == 0 {
+				continue
+			}
+
+			nsItem.Webhooks = append(nsItem.Webhooks, PrintNamespaceWebhookItem{
+				Kind:           item.Kind,
+				Name:           item.Name,
+				Webhook:        item.Webhook.Name,
+				FailurePolicy:  item.FailurePolicy,
+				ResourceModels: rules,
+			})
+		}
+		result.Items = append(result.Items, nsItem)
+	}
+	return result
+}
+
+// PrintNamespaces prints the given PrintNamespaceModel as json, yaml or a
+// table of the webhooks of each namespace. With matrix, the table instead
+// has a row per namespace and a column per webhook.
+func (p *Printer) PrintNamespaces(model *PrintNamespaceModel, matrix bool) error {
+	if p.opts.NoColor || !isTerminal(p.out) {
+		pterm.DisableColor()
+	} else {
+		pterm.EnableColor()
+	}
+
+	switch p.opts.Format {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(model)
+	case "yaml":
+		out, err := yaml.Marshal(model)
+		if err != nil {
+			return err
+		}
+		_, err = p.out.Write(out)
+		return err
+	case "", "table":
+		if matrix {
+			p.printNamespaceMatrix(model)
+		} else {
+			p.printNamespaceTable(model)
+		}
+		return nil
+	}
+	return fmt.Errorf("output format %q is not supported by the namespace view, use table, json or yaml", p.opts.Format)
+}
+
+func (p *Printer) printNamespaceTable(model *PrintNamespaceModel) {
+	var data [][]string
+	for _, ns := range model.Items {
+		if len(ns.Webhooks) == 0 {
+			data = append(data, []string{ns.Namespace, "-", "-", "-", "-", "-"})
+		}
+		for _, wh := range ns.Webhooks {
+			var rules []string
+			for _, rm := range wh.ResourceModels {
+				rules = append(rules, fmt.Sprintf("%s: %s", strings.Join(rm.Resources, ", "), strings.Join(rm.Operations, ", ")))
+			}
+
+			policy := wh.FailurePolicy
+			if policy == "Fail" {
+				policy = p.opts.Colors.style(p.opts.Colors.Warning).Sprint(policy)
+			}
+			data = append(data, []string{ns.Namespace, wh.Kind, wh.Name, wh.Webhook, strings.Join(rules, "\n"), policy})
+		}
+	}
+
+	table := tablewriter.NewWriter(p.out)
+	table.SetHeader([]string{"Namespace", "Kind", "Name", "Webhook", "Resources&Operations", "Failure Policy"})
+	table.SetRowLine(true)
+	table.SetAutoMergeCells(true)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(data)
+	table.Render()
+}
+
+func (p *Printer) printNamespaceMatrix(model *PrintNamespaceModel) {
+	var columns []string
+	index := map[string]int{}
+	for _, ns := range model.Items {
+		for _, wh := range ns.Webhooks {
+			key := namespaceColumn(wh)
+			if _, ok := index[key]; !ok {
+				index[key] = len(columns)
+				columns = append(columns, key)
+			}
+		}
+	}
+
+	var data [][]string
+	for _, ns := range model.Items {
+		row := make([]string, len(columns)+1)
+		row[0] = ns.Namespace
+		for _, wh := range ns.Webhooks {
+			mark := p.glyphs.Check
+			if wh.FailurePolicy == "Fail" {
+				mark = p.opts.Colors.style(p.opts.Colors.Warning).Sprint(mark)
+			}
+			row[index[namespaceColumn(wh)]+1] = mark
+		}
+		data = append(data, row)
+	}
+
+	table := tablewriter.NewWriter(p.out)
+	table.SetHeader(append([]string{"Namespace"}, columns...))
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(data)
+	table.Render()
+}
+
+// namespaceColumn names the matrix column of a webhook by its kind,
+// configuration and webhook, as webhook names are only unique within
+// their configuration. Policies are named once, as their webhook is
+// named after them.
+func namespaceColumn(wh PrintNamespaceWebhookItem) string {
+	if wh.Name == wh.Webhook {
+		return wh.Kind + "/" + wh.Name
+	}
+	return wh.Kind + "/" + wh.Name + "/" + wh.Webhook
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewNamespaceModel(t *testing.T) {
+	model := testModel()
+	model.Items = append(model.Items, PrintItem{
+		Name:    "namespaces",
+		Kind:    "Validating",
+		Webhook: PrintWebhookItem{Name: "namespaces.policy.io"},
+		ResourceModels: []ResourceModel{{
+			Operations: []string{"DELETE"},
+			Resources:  []string{"namespaces"},
+			Scope:      "Cluster",
+		}},
+		ActiveNamespaces: []string{"default", "kube-system"},
+	})
+
+	nsModel := NewNamespaceModel(model, []string{"default", "kube-system"})
+
+	var got []string
+	for _, ns := range nsModel.Items {
+		var webhooks []string
+		for _, wh := range ns.Webhooks {
+			webhooks = append(webhooks, wh.Kind+"/"+wh.Webhook)
+		}
+		got = append(got, ns.Namespace+": "+strings.Join(webhooks, ","))
+	}
+	want := "default: Mutating/sidecar.injector.io\nkube-system: "
+	if strings.Join(got, "\n") != want {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), want)
+	}
+}
+
+func TestPrintNamespaces(t *testing.T) {
+	nsModel := NewNamespaceModel(testModel(), []string{"default", "kube-system"})
+
+	for _, tt := range []struct {
+		name   string
+		matrix bool
+		want   []string
+	}{
+		{name: "table", want: []string{"NAMESPACE", "default", "sidecar.injector.io", "pods: CREATE, UPDATE", "kube-system"}},
+		{name: "matrix", matrix: true, want: []string{"Mutating/sidecar-injector/sidecar.injector.io", "| default     | v "}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewOptions()
+			opts.NoColor = true
+			opts.ASCII = true
+			out := &bytes.Buffer{}
+			if err := NewPrinter(out, opts).PrintNamespaces(nsModel, tt.matrix); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+
+	opts := NewOptions()
+	opts.Format = "csv"
+	if err := NewPrinter(&bytes.Buffer{}, opts).PrintNamespaces(nsModel, false); err == nil {
+		t.Error("got no error for csv, want the format to be rejected")
+	}
+}
+
+func TestNamespaceColumn(t *testing.T) {
+	for _, tt := range []struct {
+		webhook PrintNamespaceWebhookItem
+		want    string
+	}{
+		{
+			webhook: PrintNamespaceWebhookItem{Kind: "Validating", Name: "policy", Webhook: "pods.policy.io"},
+			want:    "Validating/policy/pods.policy.io",
+		},
+		{
+			webhook: PrintNamespaceWebhookItem{Kind: "Validating", Name: "legacy-policy", Webhook: "pods.policy.io"},
+			want:    "Validating/legacy-policy/pods.policy.io",
+		},
+		{
+			webhook: PrintNamespaceWebhookItem{Kind: "Policy", Name: "replicas", Webhook: "replicas"},
+			want:    "Policy/replicas",
+		},
+	} {
+		if got := namespaceColumn(tt.webhook); got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+}
@@ -22,27 +22,167 @@ import (
 	"github.com/olekukonko/tablewriter"
 	"github.com/pterm/pterm"
 	"io"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"os"
 	"strings"
 	"time"
 )
 
+// Formats lists every output format Printer supports.
+var Formats = []string{"table", "json", "yaml", "describe", "markdown", "html", "dot", "mermaid", "csv", "tsv"}
+
+// Columns lists every column of the table output format.
+var Columns = []string{"kind", "name", "webhook", "service", "resources", "remaining", "namespaces", "owner", "probe", "findings", "failures"}
+
+var columnHeaders = map[string]string{
+	"kind":       "Kind",
+	"name":       "Name",
+	"webhook":    "Webhook",
+	"service":    "Service",
+	"resources":  "Resources&Operations",
+	"remaining":  "Remaining Day",
+	"namespaces": "Active NS",
+	"owner":      "Owner",
+	"probe":      "Probe",
+	"findings":   "Findings",
+	"failures":   "Failures",
+}
+
+// Options configures how a Printer renders a PrintModel.
+type Options struct {
+	Format string
+	// Columns are the columns of the table output, all but owner, probe
+	// and findings are shown when empty.
+	Columns []string
+	Colors  ColorScheme
+	// CertWarning and CertCritical are the remaining certificate lifetimes
+	// below which the Remaining Day column is coloured as warning or critical.
+	CertWarning  time.Duration
+	CertCritical time.Duration
+	// NoColor disables colours, they are also disabled when the output
+	// is not a terminal.
+	NoColor bool
+	// ASCII draws trees, bullets and marks with ASCII characters only.
+	ASCII bool
+	// NoHeaders omits the header row of the custom-columns, csv and tsv output.
+	NoHeaders bool
+	// ListExpanded lists the resources wildcard rules expand to, not only
+	// their count.
+	ListExpanded bool
+}
+
+// NewOptions provides an instance of Options with default values
+func NewOptions() *Options {
+	return &Options{
+		Format:       "table",
+		Colors:       DefaultColorScheme,
+		CertWarning:  60000 * 24 * time.Hour,
+		CertCritical: 4000 * 24 * time.Hour,
+		NoColor:      os.Getenv("NO_COLOR") != "",
+	}
+}
+
+// Validate ensures that the format, columns and colours are supported.
+func (o *Options) Validate() error {
+	if err := ValidateFormat(o.Format); err != nil {
+		return err
+	}
+	for _, c := range o.Columns {
+		if _, ok := columnHeaders[c]; !ok {
+			return fmt.Errorf("unknown column %q, must be one of %v", c, Columns)
+		}
+	}
+	return o.Colors.Validate()
+}
+
 // Printer formats and prints check results and warnings.
 type Printer struct {
-	out io.Writer
+	out    io.Writer
+	opts   Options
+	glyphs glyphs
 }
 
 // NewPrinter constructs a new Printer with the specified output io.Writer
 // and output format.
-func NewPrinter(out io.Writer) *Printer {
+func NewPrinter(out io.Writer, opts *Options) *Printer {
+	g := unicodeGlyphs
+	if opts.ASCII {
+		g = asciiGlyphs
+	}
+
 	return &Printer{
-		out: out,
+		out:    out,
+		opts:   *opts,
+		glyphs: g,
+	}
+}
+
+// ValidateFormat ensures that the given output format is supported.
+func ValidateFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	name, arg := splitFormat(format)
+	for _, f := range TemplateFormats {
+		if name == f {
+			return validateTemplate(name, arg)
+		}
 	}
+	return fmt.Errorf("unknown output format %q, must be one of %v or %v with a template", format, Formats, TemplateFormats)
+}
+
+// Print prints the given PrintModel in the Printer's output format.
+func (p *Printer) Print(model *PrintModel) error {
+	if p.opts.NoColor || !isTerminal(p.out) {
+		pterm.DisableColor()
+	} else {
+		pterm.EnableColor()
+	}
+
+	switch p.opts.Format {
+	case "", "table":
+		p.printTable(model)
+		return nil
+	case "json":
+		return p.printJSON(model)
+	case "yaml":
+		return p.printYAML(model)
+	case "describe":
+		return p.printDescribe(model)
+	case "markdown":
+		return p.printMarkdown(model)
+	case "html":
+		return p.printHTML(model)
+	case "dot":
+		return p.printDOT(model)
+	case "mermaid":
+		return p.printMermaid(model)
+	case "csv":
+		return p.printCSV(model, ',')
+	case "tsv":
+		return p.printCSV(model, '\t')
+	}
+
+	name, arg := splitFormat(p.opts.Format)
+	switch name {
+	case "go-template":
+		return p.printGoTemplate(model, arg)
+	case "jsonpath":
+		return p.printJSONPath(model, arg)
+	case "custom-columns":
+		return p.printCustomColumns(model, arg)
+	}
+	return ValidateFormat(p.opts.Format)
 }
 
 //modifyNamespaces returns BulletListItem's for Namespaces with customizable fields in order to give custom string and styles
-func modifyNamespaces(str string) (text string, textStyle *pterm.Style, bullet string, bulletStyle *pterm.Style) {
-	return str, pterm.NewStyle(pterm.FgGreen), pterm.DefaultBulletList.Bullet, pterm.NewStyle(pterm.FgLightWhite)
+func (p *Printer) modifyNamespaces(str string) (text string, textStyle *pterm.Style, bullet string, bulletStyle *pterm.Style) {
+	return str, pterm.NewStyle(pterm.FgGreen), p.glyphs.Bullet, pterm.NewStyle(pterm.FgLightWhite)
 }
 
 type BulletItem struct {
@@ -52,7 +192,7 @@ type BulletItem struct {
 
 //convertStringArrayToBulletListItem converts given string array to
 //pterm's BulletListItem array and returns as []pterm.BulletListItem
-func convertStringArrayToBulletListItem(s BulletItem) []pterm.BulletListItem {
+func (p *Printer) convertStringArrayToBulletListItem(s BulletItem) []pterm.BulletListItem {
 	var bulletItems []pterm.BulletListItem
 
 	if s.Items != nil {
@@ -76,7 +216,7 @@ func convertStringArrayToBulletListItem(s BulletItem) []pterm.BulletListItem {
 		bulletItems = append(bulletItems, pterm.BulletListItem{
 			Level:       0,
 			Text:        "No Active Namespaces",
-			Bullet:      "✖",
+			Bullet:      p.glyphs.Cross,
 			TextStyle:   pterm.NewStyle(pterm.FgRed),
 			BulletStyle: pterm.NewStyle(pterm.FgLightRed),
 		})
@@ -84,14 +224,205 @@ func convertStringArrayToBulletListItem(s BulletItem) []pterm.BulletListItem {
 	return bulletItems
 }
 
-//Print reads given PrintModel and prints as
+//renderProbe returns the tree of the given webhook's TLS probe result.
+func (p *Printer) renderProbe(probe *PrintProbeItem) string {
+	probeLeveledList := pterm.LeveledList{}
+
+	switch {
+	case probe == nil:
+		probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 0, Text: "-"})
+	case probe.Error != "":
+		probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 0, Text: pterm.NewStyle(pterm.FgRed).Sprint(p.glyphs.Cross + " Unreachable")})
+		probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 1, Text: probe.Error})
+	default:
+		if probe.Verified {
+			probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 0, Text: pterm.NewStyle(pterm.FgGreen).Sprint(p.glyphs.Check + " Trusted")})
+		} else {
+			probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 0, Text: pterm.NewStyle(pterm.FgRed).Sprint(p.glyphs.Cross + " Untrusted")})
+			probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 1, Text: probe.VerifyError})
+		}
+		probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 1, Text: fmt.Sprintf("Via : %s (%s)", probe.Via, probe.ServerName)})
+		probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 1, Text: fmt.Sprintf("TLS : %s", probe.Latency.Round(time.Millisecond))})
+		probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 1, Text: "Cert: " + probe.ServedNotAfter.Format("2006-01-02")})
+		if !probe.CABundleNotAfter.IsZero() {
+			ca := "CA  : " + probe.CABundleNotAfter.Format("2006-01-02")
+			if probe.CABundleNotAfter.Before(probe.ServedNotAfter) {
+				ca = p.opts.Colors.style(p.opts.Colors.Warning).Sprint(ca + " (expires first)")
+			}
+			probeLeveledList = append(probeLeveledList, pterm.LeveledListItem{Level: 1, Text: ca})
+		}
+	}
+
+	pt, _ := p.glyphs.Tree.WithRoot(pterm.NewTreeFromLeveledList(probeLeveledList)).Srender()
+	return strings.TrimSuffix(pt, "\n")
+}
+
+//policyLeveledList returns the tree shown in place of the service of a
+//policy: its parameters, validations and bindings.
+func (p *Printer) policyLeveledList(policy *PrintPolicyItem) pterm.LeveledList {
+	list := pterm.LeveledList{{Level: 0, Text: "In-process (CEL)"}}
+	if policy.ParamKind != "" {
+		list = append(list, pterm.LeveledListItem{Level: 1, Text: "Params: " + policy.ParamKind})
+	}
+	for _, v := range policy.Validations {
+		list = append(list, pterm.LeveledListItem{Level: 1, Text: "Validate: " + oneLine(v.Expression)})
+	}
+	for _, m := range policy.Mutations {
+		list = append(list, pterm.LeveledListItem{Level: 1, Text: fmt.Sprintf("Mutate (%s): %s", m.PatchType, oneLine(m.Expression))})
+	}
+	if policy.ReinvocationPolicy != "" {
+		list = append(list, pterm.LeveledListItem{Level: 1, Text: "Reinvocation: " + policy.ReinvocationPolicy})
+	}
+	if len(policy.Bindings) == 0 {
+		list = append(list, pterm.LeveledListItem{Level: 1, Text: p.opts.Colors.style(p.opts.Colors.Warning).Sprint("Not bound")})
+	}
+	for _, b := range policy.Bindings {
+		list = append(list, pterm.LeveledListItem{Level: 1, Text: "Binding: " + b.Name})
+		if len(b.ValidationActions) > 0 {
+			list = append(list, pterm.LeveledListItem{Level: 2, Text: "Actions: " + strings.Join(b.ValidationActions, ", ")})
+		}
+		if b.ParamRef != "" {
+			list = append(list, pterm.LeveledListItem{Level: 2, Text: "Params : " + b.ParamRef})
+		}
+		list = append(list, pterm.LeveledListItem{Level: 2, Text: fmt.Sprintf("NS     : %d", len(b.ActiveNamespaces))})
+	}
+	return list
+}
+
+//oneLine collapses the whitespace of a multi-line CEL expression so
+//that it fits a tree item.
+func oneLine(expression string) string {
+	return strings.Join(strings.Fields(expression), " ")
+}
+
+//renderFailures renders the counts of the failed and denied requests of
+//a webhook with its most recent event messages.
+func (p *Printer) renderFailures(failures *PrintFailuresItem) string {
+	if failures == nil {
+		return "-"
+	}
+
+	style := p.opts.Colors.style(p.opts.Colors.Warning)
+	if failures.Failed > 0 {
+		style = p.opts.Colors.style(p.opts.Colors.Critical)
+	}
+	text := style.Sprintf("%s %d failed, %d denied", p.glyphs.Cross, failures.Failed, failures.Denied)
+	failuresLeveledList := pterm.LeveledList{{Level: 0, Text: text}}
+	failuresLeveledList = append(failuresLeveledList, pterm.LeveledListItem{Level: 1, Text: "Last: " + duration.HumanDuration(time.Since(failures.LastSeen)) + " ago"})
+	for _, e := range failures.Recent {
+		failuresLeveledList = append(failuresLeveledList, pterm.LeveledListItem{Level: 1, Text: p.truncate(oneLine(e.Message), 60)})
+	}
+
+	ft, _ := p.glyphs.Tree.WithRoot(pterm.NewTreeFromLeveledList(failuresLeveledList)).Srender()
+	return strings.TrimSuffix(ft, "\n")
+}
+
+//truncate shortens the given text to the given number of runes,
+//ellipsis included.
+func (p *Printer) truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-len([]rune(p.glyphs.Ellipsis))]) + p.glyphs.Ellipsis
+}
+
+//renderOwner returns the tree of the given configuration's owner and
+//where it was found.
+func (p *Printer) renderOwner(provenance *PrintProvenanceItem) string {
+	if provenance == nil {
+		return "-"
+	}
+
+	owner := provenance.Owner
+	if owner == "" {
+		owner = pterm.NewStyle(pterm.FgGray).Sprint("unknown")
+	}
+	ownerLeveledList := pterm.LeveledList{{Level: 0, Text: owner}}
+	if provenance.ManagedBy != "" {
+		ownerLeveledList = append(ownerLeveledList, pterm.LeveledListItem{Level: 1, Text: "By  : " + provenance.ManagedBy})
+	}
+	if len(provenance.Managers) > 0 {
+		ownerLeveledList = append(ownerLeveledList, pterm.LeveledListItem{Level: 1, Text: "Mgr : " + strings.Join(provenance.Managers, ", ")})
+	}
+	if provenance.InjectCAFrom != "" {
+		ownerLeveledList = append(ownerLeveledList, pterm.LeveledListItem{Level: 1, Text: "CA  : " + provenance.InjectCAFrom})
+	}
+	if !provenance.CreationTimestamp.IsZero() {
+		ownerLeveledList = append(ownerLeveledList, pterm.LeveledListItem{Level: 1, Text: fmt.Sprintf("Gen : %d, %s", provenance.Generation, provenance.CreationTimestamp.Format("2006-01-02"))})
+	}
+
+	ot, _ := p.glyphs.Tree.WithRoot(pterm.NewTreeFromLeveledList(ownerLeveledList)).Srender()
+	return strings.TrimSuffix(ot, "\n")
+}
+
+//renderFindings returns the bullet list of the given webhook's lint findings.
+func (p *Printer) renderFindings(findings []Finding) string {
+	var bulletItems []pterm.BulletListItem
+	for _, f := range findings {
+		style := pterm.NewStyle(pterm.FgLightWhite)
+		switch f.Severity {
+		case SeverityCritical:
+			style = p.opts.Colors.style(p.opts.Colors.Critical)
+		case SeverityWarning:
+			style = p.opts.Colors.style(p.opts.Colors.Warning)
+		}
+		bulletItems = append(bulletItems, pterm.BulletListItem{
+			Level:       0,
+			Text:        fmt.Sprintf("%s: %s", strings.ToUpper(string(f.Severity)), f.Message),
+			TextStyle:   style,
+			Bullet:      p.glyphs.Cross,
+			BulletStyle: style,
+		})
+	}
+	if bulletItems == nil {
+		return "-"
+	}
+	fs, _ := pterm.DefaultBulletList.WithItems(bulletItems).Srender()
+	return fs
+}
+
+//printTable reads given PrintModel and prints as
 //table using tablewriter.
-func (p *Printer) Print(model *PrintModel) {
+func (p *Printer) printTable(model *PrintModel) {
 	var data [][]string
 
+	columns := p.opts.Columns
+	if len(columns) == 0 {
+		owned, probed, linted, failing := false, false, false, false
+		for _, item := range model.Items {
+			if item.Provenance != nil && item.Provenance.Owner != "" {
+				owned = true
+			}
+			if item.Webhook.Probe != nil {
+				probed = true
+			}
+			if len(item.Findings) > 0 {
+				linted = true
+			}
+			if item.Failures != nil {
+				failing = true
+			}
+		}
+
+		columns = []string{"kind", "name", "webhook", "service", "resources", "remaining", "namespaces"}
+		if owned {
+			columns = append(columns, "owner")
+		}
+		if probed {
+			columns = append(columns, "probe")
+		}
+		if linted {
+			columns = append(columns, "findings")
+		}
+		if failing {
+			columns = append(columns, "failures")
+		}
+	}
+
 	for _, item := range model.Items {
 		namespacesData, _ := pterm.DefaultBulletList.WithItems(
-			convertStringArrayToBulletListItem(BulletItem{Items: item.ActiveNamespaces, Modify: modifyNamespaces})).Srender()
+			p.convertStringArrayToBulletListItem(BulletItem{Items: item.ActiveNamespaces, Modify: p.modifyNamespaces})).Srender()
 
 		resourcesLeveledList := pterm.LeveledList{}
 		for _, rm := range item.ResourceModels {
@@ -109,11 +440,50 @@ func (p *Printer) Print(model *PrintModel) {
 				}
 				resourcesLeveledList = append(resourcesLeveledList, pterm.LeveledListItem{Level: 1, Text: op})
 			}
+			for _, r := range rm.Unserved {
+				text := fmt.Sprintf("%s %s not served", p.glyphs.Cross, r)
+				resourcesLeveledList = append(resourcesLeveledList, pterm.LeveledListItem{Level: 0, Text: p.opts.Colors.style(p.opts.Colors.Warning).Sprint(text)})
+			}
+			for _, r := range rm.Unknown {
+				resourcesLeveledList = append(resourcesLeveledList, pterm.LeveledListItem{Level: 0, Text: fmt.Sprintf("? %s not discovered", r)})
+			}
+			if rm.Expanded != nil {
+				text := fmt.Sprintf("= %d served resources", len(rm.Expanded))
+				if len(rm.Expanded) == 1 {
+					text = "= 1 served resource"
+				}
+				resourcesLeveledList = append(resourcesLeveledList, pterm.LeveledListItem{Level: 0, Text: p.opts.Colors.style(p.opts.Colors.Warning).Sprint(text)})
+				if p.opts.ListExpanded {
+					for _, r := range rm.Expanded {
+						resourcesLeveledList = append(resourcesLeveledList, pterm.LeveledListItem{Level: 1, Text: r})
+					}
+				}
+			}
+		}
+
+		for _, rm := range item.ExcludedResourceModels {
+			for _, rs := range rm.Resources {
+				resourcesLeveledList = append(resourcesLeveledList, pterm.LeveledListItem{Level: 0, Text: "except " + rs})
+			}
+			for _, op := range rm.Operations {
+				resourcesLeveledList = append(resourcesLeveledList, pterm.LeveledListItem{Level: 1, Text: op})
+			}
+		}
+
+		for _, mc := range item.MatchConditions {
+			text := fmt.Sprintf("if %s: %s", mc.Name, oneLine(mc.Expression))
+			if mc.Error != "" {
+				text = p.opts.Colors.style(p.opts.Colors.Critical).Sprintf("%s %s", p.glyphs.Cross, text)
+			}
+			resourcesLeveledList = append(resourcesLeveledList, pterm.LeveledListItem{Level: 0, Text: text})
 		}
 
 		remainingTime := func(t time.Duration) string {
+			if item.Policy != nil {
+				return "-"
+			}
 			if t == 0 {
-				return pterm.Red("No CABundle")
+				return p.opts.Colors.style(p.opts.Colors.Critical).Sprint("No CABundle")
 			}
 			days := t.Hours() / 24
 
@@ -127,12 +497,12 @@ func (p *Printer) Print(model *PrintModel) {
 
 			str := durafmt.Parse(t).LimitFirstN(N()).String()
 
-			if days < 4000 {
-				return pterm.Red(str)
-			} else if days < 60000 {
-				return pterm.Yellow(str)
+			if t < p.opts.CertCritical {
+				return p.opts.Colors.style(p.opts.Colors.Critical).Sprint(str)
+			} else if t < p.opts.CertWarning {
+				return p.opts.Colors.style(p.opts.Colors.Warning).Sprint(str)
 			} else {
-				return pterm.Green(str)
+				return p.opts.Colors.style(p.opts.Colors.OK).Sprint(str)
 			}
 		}
 
@@ -148,36 +518,63 @@ func (p *Printer) Print(model *PrintModel) {
 			}
 			serviceLeveledList = append(serviceLeveledList, pterm.LeveledListItem{Level: 1, Text: fmt.Sprintf("IP  : %s (%s)", service.ClusterIP, service.Type)})
 			if service.Ports != nil {
-				for _, p := range service.Ports {
+				for _, port := range service.Ports {
 					getPortInfo := func() string {
-						if p.TargetPort == 0 {
-							return fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+						if port.TargetPort == 0 {
+							return fmt.Sprintf("%d/%s", port.Port, port.Protocol)
 						}
-						return fmt.Sprintf("%d::%d/%s", p.Port, p.TargetPort, p.Protocol)
+						return fmt.Sprintf("%d::%d/%s", port.Port, port.TargetPort, port.Protocol)
 					}
 					serviceLeveledList = append(serviceLeveledList, pterm.LeveledListItem{Level: 2, Text: getPortInfo()})
 				}
 			}
 		} else {
-			serviceLeveledList = append(serviceLeveledList, pterm.LeveledListItem{Level: 0, Text: pterm.NewStyle(pterm.FgRed).Sprintf("✖ %s", service.Name)})
+			serviceLeveledList = append(serviceLeveledList, pterm.LeveledListItem{Level: 0, Text: pterm.NewStyle(pterm.FgRed).Sprintf("%s %s", p.glyphs.Cross, service.Name)})
 			serviceLeveledList = append(serviceLeveledList, pterm.LeveledListItem{Level: 1, Text: "NS  : " + service.Namespace})
 		}
 
+		if item.Policy != nil {
+			serviceLeveledList = p.policyLeveledList(item.Policy)
+		}
+
 		if len(serviceLeveledList) == 0 {
-			serviceLeveledList = append(serviceLeveledList, pterm.LeveledListItem{Level: 0, Text: pterm.NewStyle(pterm.FgRed).Sprint("✖ No Services")})
+			serviceLeveledList = append(serviceLeveledList, pterm.LeveledListItem{Level: 0, Text: pterm.NewStyle(pterm.FgRed).Sprint(p.glyphs.Cross + " No Services")})
 		}
 
 		webhookTreeList := pterm.NewTreeFromLeveledList(serviceLeveledList)
 		resourcesTreeList := pterm.NewTreeFromLeveledList(resourcesLeveledList)
 
-		wt, _ := pterm.DefaultTree.WithRoot(webhookTreeList).Srender()
-		rt, _ := pterm.DefaultTree.WithRoot(resourcesTreeList).Srender()
+		wt, _ := p.glyphs.Tree.WithRoot(webhookTreeList).Srender()
+		rt, _ := p.glyphs.Tree.WithRoot(resourcesTreeList).Srender()
+
+		cells := map[string]string{
+			"kind":       item.Kind,
+			"name":       item.Name,
+			"webhook":    item.Webhook.Name,
+			"service":    strings.TrimSuffix(wt, "\n"),
+			"resources":  strings.TrimSuffix(rt, "\n"),
+			"remaining":  remainingTime(item.ValidUntil),
+			"namespaces": namespacesData,
+			"owner":      p.renderOwner(item.Provenance),
+			"probe":      p.renderProbe(item.Webhook.Probe),
+			"findings":   p.renderFindings(item.Findings),
+			"failures":   p.renderFailures(item.Failures),
+		}
+
+		var row []string
+		for _, c := range columns {
+			row = append(row, cells[c])
+		}
+		data = append(data, row)
+	}
 
-		data = append(data, []string{item.Kind, item.Name, item.Webhook.Name, strings.TrimSuffix(wt, "\n"), strings.TrimSuffix(rt, "\n"), remainingTime(item.ValidUntil), namespacesData})
+	var header []string
+	for _, c := range columns {
+		header = append(header, columnHeaders[c])
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Kind", "Name", "Webhook", "Service", "Resources&Operations", "Remaining Day", "Active NS"})
+	table := tablewriter.NewWriter(p.out)
+	table.SetHeader(header)
 	table.SetRowLine(true)
 	table.SetAutoMergeCells(true)
 	table.SetHeaderLine(true)
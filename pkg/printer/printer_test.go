@@ -0,0 +1,173 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testModel returns a mutating webhook on pods failing closed, with a
+// probe and a finding, next to a validating webhook without a CABundle.
+func testModel() *PrintModel {
+	path := "/mutate"
+	port := int32(443)
+	timeout := int32(5)
+	return &PrintModel{Items: []PrintItem{
+		{
+			Name: "sidecar-injector",
+			Kind: "Mutating",
+			Webhook: PrintWebhookItem{
+				Name: "sidecar.injector.io",
+				Service: PrintServiceItem{
+					Found:     true,
+					Name:      "injector",
+					Namespace: "mesh",
+					Path:      &path,
+					Ports:     []PrintServicePortItem{{Port: port, TargetPort: 8443, Protocol: "TCP"}},
+				},
+				Probe: &PrintProbeItem{
+					Via:        "url",
+					ServerName: "injector.mesh.svc",
+					Verified:   true,
+				},
+			},
+			ResourceModels: []ResourceModel{{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Operations:  []string{"CREATE", "UPDATE"},
+				Resources:   []string{"pods"},
+				Scope:       "Namespaced",
+			}},
+			ValidUntil:       400 * 24 * time.Hour,
+			ActiveNamespaces: []string{"default", "mesh"},
+			FailurePolicy:    "Fail",
+			TimeoutSeconds:   &timeout,
+			Findings: []Finding{{
+				Check:    "self-interception",
+				Severity: SeverityCritical,
+				Message:  "blocks its own recovery",
+			}},
+		},
+		{
+			Name: "deny-all",
+			Kind: "Validating",
+			Webhook: PrintWebhookItem{
+				Name: "deny.all.io",
+			},
+			ResourceModels: []ResourceModel{{
+				APIGroups:   []string{"apps"},
+				APIVersions: []string{"v1"},
+				Operations:  []string{"DELETE"},
+				Resources:   []string{"deployments"},
+				Scope:       "*",
+			}},
+			FailurePolicy: "Ignore",
+		},
+	}}
+}
+
+// render prints the test model with the given options, which are
+// completed with the defaults, ASCII glyphs and no colours.
+func render(t *testing.T, modify func(o *Options)) string {
+	t.Helper()
+	return renderModel(t, testModel(), modify)
+}
+
+// renderModel prints the given model like render.
+func renderModel(t *testing.T, model *PrintModel, modify func(o *Options)) string {
+	t.Helper()
+	opts := NewOptions()
+	opts.NoColor = true
+	opts.ASCII = true
+	modify(opts)
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	if err := NewPrinter(out, opts).Print(model); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestPrintTable(t *testing.T) {
+	out := render(t, func(o *Options) {})
+
+	for _, want := range []string{"sidecar-injector", "sidecar.injector.io", "deny-all", "pods", "+CREATE", "No CABundle", "mesh", "v Trusted"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("table does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Errorf("table contains colours:\n%q", out)
+	}
+	for _, glyph := range []string{"✖", "✔", "└", "•"} {
+		if strings.Contains(out, glyph) {
+			t.Errorf("ASCII table contains %q:\n%s", glyph, out)
+		}
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	out := render(t, func(o *Options) { o.Format = "json" })
+
+	model := &PrintModel{}
+	if err := json.Unmarshal([]byte(out), model); err != nil {
+		t.Fatalf("invalid JSON %v:\n%s", err, out)
+	}
+	if len(model.Items) != 2 || model.Items[0].Findings[0].Check != "self-interception" {
+		t.Errorf("unexpected model %+v", model)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	opts := NewOptions()
+	opts.Format = "xml"
+	if err := opts.Validate(); err == nil {
+		t.Error("format xml is valid, want an error")
+	}
+
+	opts = NewOptions()
+	opts.Columns = []string{"name", "owner-team"}
+	if err := opts.Validate(); err == nil || !strings.Contains(err.Error(), `unknown column "owner-team"`) {
+		t.Errorf("error = %v, want an unknown column", err)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	message := `failed calling webhook "pods.policy.io": context deadline exceeded`
+	for _, tt := range []struct {
+		ascii bool
+		n     int
+		want  string
+	}{
+		{n: 80, want: message},
+		{n: 20, want: `failed calling webh…`},
+		{ascii: true, n: 20, want: `failed calling we...`},
+	} {
+		opts := NewOptions()
+		opts.ASCII = tt.ascii
+		if got := NewPrinter(&bytes.Buffer{}, opts).truncate(message, tt.n); got != tt.want {
+			t.Errorf("truncate(%d, ascii %v) = %q, want %q", tt.n, tt.ascii, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"fmt"
+	"github.com/hako/durafmt"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// reportConfiguration is a webhook configuration with its webhooks, the
+// unit the markdown and html reports are laid out in.
+type reportConfiguration struct {
+	Kind  string
+	Name  string
+	Items []PrintItem
+}
+
+// report is the data the markdown and html reports are rendered from.
+type report struct {
+	Generated      time.Time
+	Configurations []reportConfiguration
+	Webhooks       int
+	Findings       map[string]int
+}
+
+func newReport(model *PrintModel) report {
+	r := report{
+		Generated: time.Now(),
+		Findings:  map[string]int{},
+	}
+
+	index := map[string]int{}
+	for _, item := range model.Items {
+		key := item.Kind + "/" + item.Name
+		i, ok := index[key]
+		if !ok {
+			i = len(r.Configurations)
+			index[key] = i
+			r.Configurations = append(r.Configurations, reportConfiguration{Kind: item.Kind, Name: item.Name})
+		}
+		r.Configurations[i].Items = append(r.Configurations[i].Items, item)
+		r.Webhooks++
+		for _, f := range item.Findings {
+			r.Findings[string(f.Severity)]++
+		}
+	}
+	return r
+}
+
+// certLevel classifies the remaining CABundle lifetime of the given item
+// by the configured thresholds. Policies have no CABundle to classify.
+func (p *Printer) certLevel(item PrintItem) Severity {
+	t := item.ValidUntil
+	switch {
+	case item.Policy != nil:
+		return ""
+	case t == 0 || t < p.opts.CertCritical:
+		return SeverityCritical
+	case t < p.opts.CertWarning:
+		return SeverityWarning
+	}
+	return "ok"
+}
+
+func certText(item PrintItem) string {
+	t := item.ValidUntil
+	if item.Policy != nil {
+		return "-"
+	}
+	if t == 0 {
+		return "No CABundle"
+	}
+	if t < 0 {
+		return "Expired"
+	}
+	return durafmt.Parse(t).LimitFirstN(2).String()
+}
+
+func serviceText(s PrintServiceItem) string {
+	if s.Name == "" {
+		return "-"
+	}
+	text := s.Namespace + "/" + s.Name
+	if s.Path != nil {
+		text += *s.Path
+	}
+	if !s.Found {
+		text += " (not found)"
+	}
+	return text
+}
+
+func rulesText(rms []ResourceModel) []string {
+	var rules []string
+	for _, rm := range rms {
+		rules = append(rules, fmt.Sprintf("%s: %s", strings.Join(rm.Resources, ", "), strings.Join(rm.Operations, ", ")))
+	}
+	return rules
+}
+
+func timeoutText(timeout *int32) string {
+	if timeout == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%ds", *timeout)
+}
+
+var markdownCertMarks = map[Severity]string{
+	"ok":             "🟢",
+	SeverityWarning:  "🟡",
+	SeverityCritical: "🔴",
+}
+
+// markdownEscape escapes the characters that would break a table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+// printMarkdown prints the given PrintModel as a markdown report.
+func (p *Printer) printMarkdown(model *PrintModel) error {
+	r := newReport(model)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Admission Control Report\n\n")
+	fmt.Fprintf(&b, "Generated at %s: %d configurations, %d webhooks, %d critical and %d warning findings.\n",
+		r.Generated.Format(time.RFC3339), len(r.Configurations), r.Webhooks, r.Findings[string(SeverityCritical)], r.Findings[string(SeverityWarning)])
+
+	for _, c := range r.Configurations {
+		fmt.Fprintf(&b, "\n## %s `%s`\n\n", c.Kind, c.Name)
+		fmt.Fprintf(&b, "| Webhook | Service | Rules | Failure Policy | Timeout | Certificate | Namespaces |\n")
+		fmt.Fprintf(&b, "|---------|---------|-------|----------------|---------|-------------|------------|\n")
+		for _, item := range c.Items {
+			namespaces := fmt.Sprintf("%d", len(item.ActiveNamespaces))
+			if len(item.ActiveNamespaces) > 0 {
+				namespaces += ": " + strings.Join(item.ActiveNamespaces, ", ")
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s %s | %s |\n",
+				markdownEscape(item.Webhook.Name),
+				markdownEscape(serviceText(item.Webhook.Service)),
+				markdownEscape(strings.Join(rulesText(item.ResourceModels), "<br>")),
+				item.FailurePolicy,
+				timeoutText(item.TimeoutSeconds),
+				markdownCertMarks[p.certLevel(item)], certText(item),
+				markdownEscape(namespaces))
+		}
+
+		var findings []string
+		for _, item := range c.Items {
+			for _, f := range item.Findings {
+				findings = append(findings, fmt.Sprintf("- **%s** `%s` %s: %s", f.Severity, item.Webhook.Name, f.Check, f.Message))
+			}
+		}
+		if len(findings) > 0 {
+			fmt.Fprintf(&b, "\n### Findings\n\n%s\n", strings.Join(findings, "\n"))
+		}
+	}
+
+	_, err := fmt.Fprint(p.out, b.String())
+	return err
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"service": serviceText,
+	"rules":   rulesText,
+	"timeout": timeoutText,
+	"cert":    certText,
+	"join":    strings.Join,
+	// replaced by the Printer's thresholds in printHTML
+	"certLevel": func(PrintItem) Severity { return "" },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Admission Control Report</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border: 1px solid #d1d5da; padding: 6px 10px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+ul { margin: 0; padding-left: 1.2em; }
+.ok { color: #22863a; }
+.warning { color: #b08800; }
+.critical { color: #cb2431; font-weight: bold; }
+.summary { color: #586069; }
+</style>
+</head>
+<body>
+<h1>Admission Control Report</h1>
+<p class="summary">Generated at {{ .Generated.Format "2006-01-02T15:04:05Z07:00" }}: {{ len .Configurations }} configurations, {{ .Webhooks }} webhooks,
+<span class="critical">{{ index .Findings "critical" }} critical</span> and <span class="warning">{{ index .Findings "warning" }} warning</span> findings.</p>
+{{ range .Configurations }}
+<h2>{{ .Kind }} <code>{{ .Name }}</code></h2>
+<table>
+<tr><th>Webhook</th><th>Service</th><th>Rules</th><th>Failure Policy</th><th>Timeout</th><th>Certificate</th><th>Namespaces</th><th>Findings</th></tr>
+{{ range .Items }}<tr>
+<td>{{ .Webhook.Name }}</td>
+<td>{{ service .Webhook.Service }}</td>
+<td><ul>{{ range rules .ResourceModels }}<li>{{ . }}</li>{{ end }}</ul></td>
+<td>{{ .FailurePolicy }}</td>
+<td>{{ timeout .TimeoutSeconds }}</td>
+<td class="{{ certLevel . }}">{{ cert . }}</td>
+<td>{{ if .ActiveNamespaces }}<details><summary>{{ len .ActiveNamespaces }}</summary>{{ join .ActiveNamespaces ", " }}</details>{{ else }}0{{ end }}</td>
+<td>{{ if .Findings }}<ul>{{ range .Findings }}<li class="{{ .Severity }}">{{ .Check }}: {{ .Message }}</li>{{ end }}</ul>{{ else }}-{{ end }}</td>
+</tr>
+{{ end }}</table>
+{{ end }}
+</body>
+</html>
+`))
+
+// printHTML prints the given PrintModel as a self-contained html report.
+func (p *Printer) printHTML(model *PrintModel) error {
+	t, err := htmlReport.Clone()
+	if err != nil {
+		return err
+	}
+	t.Funcs(template.FuncMap{"certLevel": p.certLevel})
+	return t.Execute(p.out, newReport(model))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCertLevel(t *testing.T) {
+	opts := NewOptions()
+	opts.CertWarning = 30 * 24 * time.Hour
+	opts.CertCritical = 7 * 24 * time.Hour
+	p := NewPrinter(nil, opts)
+
+	for remaining, want := range map[time.Duration]Severity{
+		0:                    SeverityCritical,
+		-time.Hour:           SeverityCritical,
+		3 * 24 * time.Hour:   SeverityCritical,
+		10 * 24 * time.Hour:  SeverityWarning,
+		100 * 24 * time.Hour: "ok",
+	} {
+		if got := p.certLevel(PrintItem{ValidUntil: remaining}); got != want {
+			t.Errorf("certLevel(%s) = %s, want %s", remaining, got, want)
+		}
+	}
+
+	if got := p.certLevel(PrintItem{Policy: &PrintPolicyItem{}}); got != "" {
+		t.Errorf("certLevel(policy) = %s, want no level", got)
+	}
+}
+
+func TestPrintMarkdown(t *testing.T) {
+	out := render(t, func(o *Options) {
+		o.Format = "markdown"
+		o.CertWarning = 30 * 24 * time.Hour
+		o.CertCritical = 7 * 24 * time.Hour
+	})
+
+	for _, want := range []string{
+		"2 configurations, 2 webhooks, 1 critical and 0 warning findings.",
+		"## Mutating `sidecar-injector`",
+		"| sidecar.injector.io | mesh/injector/mutate | pods: CREATE, UPDATE | Fail | 5s | 🟢 1 year 5 weeks | 2: default, mesh |",
+		"| deny.all.io | - | deployments: DELETE | Ignore | - | 🔴 No CABundle | 0 |",
+		"- **critical** `sidecar.injector.io` self-interception: blocks its own recovery",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestPrintHTML(t *testing.T) {
+	out := render(t, func(o *Options) {
+		o.Format = "html"
+		o.CertWarning = 30 * 24 * time.Hour
+		o.CertCritical = 7 * 24 * time.Hour
+	})
+
+	for _, want := range []string{
+		"<h2>Mutating <code>sidecar-injector</code></h2>",
+		`<td class="ok">1 year 5 weeks</td>`,
+		`<td class="critical">No CABundle</td>`,
+		`<li class="critical">self-interception: blocks its own recovery</li>`,
+		"<details><summary>2</summary>default, mesh</details>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("html does not contain %q:\n%s", want, out)
+		}
+	}
+}
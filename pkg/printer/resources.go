@@ -0,0 +1,125 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pterm/pterm"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// APIResource is a resource, or subresource as "resource/subresource",
+// served by the cluster.
+type APIResource struct {
+	Group      string   `json:"group"`
+	Version    string   `json:"version"`
+	Resource   string   `json:"resource"`
+	Kind       string   `json:"kind"`
+	Namespaced bool     `json:"namespaced"`
+	Verbs      []string `json:"verbs,omitempty"`
+}
+
+// GroupVersion returns the API group and version of the resource in
+// apiVersion notation.
+func (r APIResource) GroupVersion() string {
+	if r.Group == "" {
+		return r.Version
+	}
+	return r.Group + "/" + r.Version
+}
+
+// Subresource tells whether the APIResource is a subresource.
+func (r APIResource) Subresource() bool {
+	return strings.Contains(r.Resource, "/")
+}
+
+// PrintResourceModel is the PrintModel inverted by API resource: the
+// webhooks and policies intercepting each operation on each resource.
+type PrintResourceModel struct {
+	Items []PrintResourceItem `json:"items"`
+}
+
+type PrintResourceItem struct {
+	APIResource `json:",inline"`
+	Operation   string   `json:"operation"`
+	Mutating    []string `json:"mutating"`
+	Validating  []string `json:"validating"`
+}
+
+// PrintResources prints the given PrintResourceModel as json, yaml or a
+// table with a row per resource and operation.
+func (p *Printer) PrintResources(model *PrintResourceModel) error {
+	if p.opts.NoColor || !isTerminal(p.out) {
+		pterm.DisableColor()
+	} else {
+		pterm.EnableColor()
+	}
+
+	switch p.opts.Format {
+	case "json":
+		encoder := json.NewEncoder(p.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(model)
+	case "yaml":
+		out, err := yaml.Marshal(model)
+		if err != nil {
+			return err
+		}
+		_, err = p.out.Write(out)
+		return err
+	case "", "table":
+		p.printResourceTable(model)
+		return nil
+	}
+	return fmt.Errorf("output format %q is not supported by the resource view, use table, json or yaml", p.opts.Format)
+}
+
+func (p *Printer) printResourceTable(model *PrintResourceModel) {
+	none := p.opts.Colors.style(p.opts.Colors.Warning).Sprint("-")
+
+	var data [][]string
+	var last APIResource
+	for i, item := range model.Items {
+		mutating, validating := strings.Join(item.Mutating, "\n"), strings.Join(item.Validating, "\n")
+		if mutating == "" {
+			mutating = "-"
+		}
+		if validating == "" {
+			validating = none
+		}
+		// the API version and resource are only shown on their first row
+		groupVersion, resource := item.GroupVersion(), item.Resource
+		if i > 0 && item.GroupVersion() == last.GroupVersion() {
+			groupVersion = ""
+			if item.Resource == last.Resource {
+				resource = ""
+			}
+		}
+		last = item.APIResource
+		data = append(data, []string{groupVersion, resource, item.Operation, mutating, validating})
+	}
+
+	table := tablewriter.NewWriter(p.out)
+	table.SetHeader([]string{"API Version", "Resource", "Operation", "Mutating", "Validating"})
+	table.SetRowLine(true)
+	table.SetAutoWrapText(false)
+	table.AppendBulk(data)
+	table.Render()
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrintResources(t *testing.T) {
+	pods := APIResource{Version: "v1", Resource: "pods", Kind: "Pod", Namespaced: true}
+	model := &PrintResourceModel{Items: []PrintResourceItem{
+		{APIResource: pods, Operation: "CREATE", Mutating: []string{"sidecar-injector/sidecar.injector.io"}, Validating: []string{"pods (policy)"}},
+		{APIResource: pods, Operation: "UPDATE"},
+	}}
+
+	opts := NewOptions()
+	opts.NoColor = true
+	out := &bytes.Buffer{}
+	if err := NewPrinter(out, opts).PrintResources(model); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| v1          | pods     | CREATE    | sidecar-injector/sidecar.injector.io | pods (policy) |",
+		"|             |          | UPDATE    | -                                    | -             |",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("table does not contain %q:\n%s", want, out)
+		}
+	}
+
+	opts.Format = "json"
+	out.Reset()
+	if err := NewPrinter(out, opts).PrintResources(model); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"resource": "pods"`) || !strings.Contains(out.String(), `"operation": "UPDATE"`) {
+		t.Errorf("unexpected JSON:\n%s", out)
+	}
+}
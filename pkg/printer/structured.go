@@ -0,0 +1,39 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"encoding/json"
+	"sigs.k8s.io/yaml"
+)
+
+// printJSON prints the given PrintModel as indented JSON.
+func (p *Printer) printJSON(model *PrintModel) error {
+	encoder := json.NewEncoder(p.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(model)
+}
+
+// printYAML prints the given PrintModel as YAML.
+func (p *Printer) printYAML(model *PrintModel) error {
+	out, err := yaml.Marshal(model)
+	if err != nil {
+		return err
+	}
+	_, err = p.out.Write(out)
+	return err
+}
@@ -0,0 +1,183 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"k8s.io/client-go/util/jsonpath"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// TemplateFormats lists the output formats that carry their template
+// after a "=", e.g. "jsonpath={.items[*].name}".
+var TemplateFormats = []string{"go-template", "jsonpath", "custom-columns"}
+
+// splitFormat splits "jsonpath={.items}" into "jsonpath" and "{.items}".
+func splitFormat(format string) (string, string) {
+	parts := strings.SplitN(format, "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// validateTemplate ensures that the template of the given template
+// format parses.
+func validateTemplate(name, arg string) error {
+	if arg == "" {
+		return fmt.Errorf("output format %s requires a template, e.g. %s=...", name, name)
+	}
+	switch name {
+	case "go-template":
+		_, err := template.New(name).Parse(arg)
+		return err
+	case "jsonpath":
+		return jsonpath.New(name).Parse(arg)
+	case "custom-columns":
+		_, err := parseCustomColumns(arg)
+		return err
+	}
+	return nil
+}
+
+// generic converts the given PrintModel into maps and slices keyed by its
+// JSON field names, which is what templates and JSONPath are evaluated on.
+func generic(model *PrintModel) (interface{}, error) {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	err = json.Unmarshal(data, &obj)
+	return obj, err
+}
+
+// printGoTemplate prints the given PrintModel through a Go template.
+func (p *Printer) printGoTemplate(model *PrintModel, text string) error {
+	t, err := template.New("output").Parse(text)
+	if err != nil {
+		return err
+	}
+	obj, err := generic(model)
+	if err != nil {
+		return err
+	}
+	return t.Execute(p.out, obj)
+}
+
+// printJSONPath prints the given PrintModel through a JSONPath template.
+func (p *Printer) printJSONPath(model *PrintModel, text string) error {
+	jp := jsonpath.New("output").AllowMissingKeys(true)
+	if err := jp.Parse(text); err != nil {
+		return err
+	}
+	obj, err := generic(model)
+	if err != nil {
+		return err
+	}
+	return jp.Execute(p.out, obj)
+}
+
+type customColumn struct {
+	Header string
+	Path   *jsonpath.JSONPath
+}
+
+// parseCustomColumns parses "KIND:.kind,NAME:.name" into its columns.
+func parseCustomColumns(spec string) ([]customColumn, error) {
+	var columns []customColumn
+	for _, c := range strings.Split(spec, ",") {
+		parts := strings.SplitN(c, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid custom column %q, expected HEADER:.path", c)
+		}
+		jp := jsonpath.New(parts[0]).AllowMissingKeys(true)
+		if err := jp.Parse(relaxedJSONPath(parts[1])); err != nil {
+			return nil, fmt.Errorf("invalid custom column %q: %v", c, err)
+		}
+		columns = append(columns, customColumn{Header: parts[0], Path: jp})
+	}
+	return columns, nil
+}
+
+// relaxedJSONPath turns ".kind" or "kind" into "{.kind}", as kubectl does.
+func relaxedJSONPath(path string) string {
+	if strings.HasPrefix(path, "{") {
+		return path
+	}
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+// printCustomColumns prints one row per webhook with the given columns.
+func (p *Printer) printCustomColumns(model *PrintModel, spec string) error {
+	columns, err := parseCustomColumns(spec)
+	if err != nil {
+		return err
+	}
+	obj, err := generic(model)
+	if err != nil {
+		return err
+	}
+	items, _ := obj.(map[string]interface{})["items"].([]interface{})
+
+	w := tabwriter.NewWriter(p.out, 10, 4, 3, ' ', 0)
+	if !p.opts.NoHeaders {
+		var headers []string
+		for _, c := range columns {
+			headers = append(headers, c.Header)
+		}
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+	}
+
+	for _, item := range items {
+		var cells []string
+		for _, c := range columns {
+			results, err := c.Path.FindResults(item)
+			if err != nil {
+				return err
+			}
+			var values []string
+			for _, r := range results {
+				for _, v := range r {
+					if v.Kind() == reflect.Interface && v.IsNil() {
+						continue
+					}
+					var buf bytes.Buffer
+					if err := c.Path.PrintResults(&buf, []reflect.Value{v}); err != nil {
+						return err
+					}
+					values = append(values, buf.String())
+				}
+			}
+			if len(values) == 0 {
+				cells = append(cells, "<none>")
+			} else {
+				cells = append(cells, strings.Join(values, ","))
+			}
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,77 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintTemplates(t *testing.T) {
+	tests := []struct {
+		format    string
+		noHeaders bool
+		want      string
+	}{
+		{
+			format: `go-template={{range .items}}{{.name}}:{{.failurePolicy}} {{end}}`,
+			want:   "sidecar-injector:Fail deny-all:Ignore ",
+		},
+		{
+			format: `jsonpath={.items[?(@.failurePolicy=="Fail")].webhook.service.namespace}`,
+			want:   "mesh",
+		},
+		{
+			format: "custom-columns=KIND:.kind,NAME:.name,NS:.activeNamespaces[*]",
+			want: "KIND         NAME               NS\n" +
+				"Mutating     sidecar-injector   default,mesh\n" +
+				"Validating   deny-all           <none>\n",
+		},
+		{
+			format:    "custom-columns=NAME:name",
+			noHeaders: true,
+			want:      "sidecar-injector\ndeny-all\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			out := render(t, func(o *Options) {
+				o.Format = tt.format
+				o.NoHeaders = tt.noHeaders
+			})
+			if out != tt.want {
+				t.Errorf("got\n%q\nwant\n%q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTemplateFormats(t *testing.T) {
+	for format, want := range map[string]string{
+		"go-template":                 "requires a template",
+		"go-template={{.items":        "unclosed action",
+		"jsonpath={.items[}":          "unterminated array",
+		"custom-columns=NAME":         "expected HEADER:.path",
+		"custom-columns=NAME:.name{{": "invalid custom column",
+		"xml=<items/>":                "unknown output format",
+	} {
+		if err := ValidateFormat(format); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error = %v, want it to contain %q", format, err, want)
+		}
+	}
+}
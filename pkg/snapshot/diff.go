@@ -0,0 +1,248 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"io"
+	"sort"
+)
+
+type ChangeType string
+
+const (
+	Added   ChangeType = "added"
+	Removed ChangeType = "removed"
+	Changed ChangeType = "changed"
+)
+
+// Change is a webhook that was added, removed or changed between two models.
+type Change struct {
+	Type          ChangeType    `json:"type"`
+	Kind          string        `json:"kind"`
+	Configuration string        `json:"configuration"`
+	Webhook       string        `json:"webhook"`
+	Fields        []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldChange is a single field of a webhook that differs.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Field extracts a comparable value from a webhook.
+type Field struct {
+	Name  string
+	Value func(item printer.PrintItem) string
+}
+
+// DiffFields are the webhook fields Diff compares.
+var DiffFields = []Field{
+	{Name: "rules", Value: rules},
+	{Name: "namespaceSelector", Value: func(item printer.PrintItem) string { return compact(item.NamespaceSelector) }},
+	{Name: "objectSelector", Value: func(item printer.PrintItem) string { return compact(item.ObjectSelector) }},
+	{Name: "matchConditions", Value: matchConditions},
+	{Name: "failurePolicy", Value: func(item printer.PrintItem) string { return item.FailurePolicy }},
+	{Name: "timeoutSeconds", Value: func(item printer.PrintItem) string { return compact(item.TimeoutSeconds) }},
+	{Name: "caBundle", Value: func(item printer.PrintItem) string { return item.CABundleFingerprint }},
+	{Name: "caIssuer", Value: func(item printer.PrintItem) string { return item.CABundleIssuer }},
+	{Name: "target", Value: Target},
+	{Name: "policy", Value: policy},
+}
+
+// Without returns the given fields except the named ones.
+func Without(fields []Field, names ...string) []Field {
+	var result []Field
+	for _, f := range fields {
+		skip := false
+		for _, name := range names {
+			if f.Name == name {
+				skip = true
+			}
+		}
+		if !skip {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// Target returns where the given webhook sends its requests to.
+func Target(item printer.PrintItem) string {
+	if item.Policy != nil {
+		return "in-process"
+	}
+	if item.Webhook.URL != nil {
+		return *item.Webhook.URL
+	}
+	s := item.Webhook.Service
+	target := fmt.Sprintf("%s/%s", s.Namespace, s.Name)
+	if s.Port != nil {
+		target += fmt.Sprintf(":%d", *s.Port)
+	}
+	if s.Path != nil {
+		target += *s.Path
+	}
+	return target
+}
+
+// rules returns the rules of an item without their wildcard expansion
+// and unserved resources, which change whenever the cluster serves a new
+// resource.
+func rules(item printer.PrintItem) string {
+	var resourceModels []printer.ResourceModel
+	for _, rm := range item.ResourceModels {
+		rm.Expanded, rm.Unserved, rm.Unknown = nil, nil, nil
+		resourceModels = append(resourceModels, rm)
+	}
+	return compact(resourceModels)
+}
+
+// matchConditions returns the matchConditions of an item without their
+// compilation errors, which depend on the CEL environment of the release
+// comparing them rather than on the webhook.
+func matchConditions(item printer.PrintItem) string {
+	var conditions []printer.PrintMatchConditionItem
+	for _, mc := range item.MatchConditions {
+		mc.Error = ""
+		conditions = append(conditions, mc)
+	}
+	return compact(conditions)
+}
+
+// policy returns the policy of an item without the namespaces its
+// bindings are active in, which change whenever a namespace is created,
+// as the namespaces of webhooks are not compared either.
+func policy(item printer.PrintItem) string {
+	if item.Policy == nil {
+		return ""
+	}
+	p := *item.Policy
+	p.Bindings = nil
+	for _, b := range item.Policy.Bindings {
+		b.ActiveNamespaces = nil
+		p.Bindings = append(p.Bindings, b)
+	}
+	return compact(p)
+}
+
+func compact(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return ""
+	}
+	return string(data)
+}
+
+// Key identifies a webhook across models.
+type Key struct {
+	Kind          string
+	Configuration string
+	Webhook       string
+}
+
+// KeyOf returns the Key of the given webhook.
+func KeyOf(item printer.PrintItem) Key {
+	return Key{Kind: item.Kind, Configuration: item.Name, Webhook: item.Webhook.Name}
+}
+
+// Diff reports the webhooks added, removed or changed from the old to the
+// new model, comparing the given fields.
+func Diff(old, new *printer.PrintModel, fields []Field) []Change {
+	oldItems := index(old)
+	newItems := index(new)
+
+	var changes []Change
+	for key, o := range oldItems {
+		n, ok := newItems[key]
+		if !ok {
+			changes = append(changes, Change{Type: Removed, Kind: key.Kind, Configuration: key.Configuration, Webhook: key.Webhook})
+			continue
+		}
+
+		var fc []FieldChange
+		for _, f := range fields {
+			if ov, nv := f.Value(o), f.Value(n); ov != nv {
+				fc = append(fc, FieldChange{Field: f.Name, Old: ov, New: nv})
+			}
+		}
+		if len(fc) > 0 {
+			changes = append(changes, Change{Type: Changed, Kind: key.Kind, Configuration: key.Configuration, Webhook: key.Webhook, Fields: fc})
+		}
+	}
+	for key := range newItems {
+		if _, ok := oldItems[key]; !ok {
+			changes = append(changes, Change{Type: Added, Kind: key.Kind, Configuration: key.Configuration, Webhook: key.Webhook})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Configuration != b.Configuration {
+			return a.Configuration < b.Configuration
+		}
+		return a.Webhook < b.Webhook
+	})
+	return changes
+}
+
+func index(model *printer.PrintModel) map[Key]printer.PrintItem {
+	items := map[Key]printer.PrintItem{}
+	for _, item := range model.Items {
+		items[KeyOf(item)] = item
+	}
+	return items
+}
+
+var changeMarks = map[ChangeType]string{
+	Added:   "+",
+	Removed: "-",
+	Changed: "~",
+}
+
+// WriteChanges writes the given changes in a human readable form.
+func WriteChanges(w io.Writer, changes []Change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "No differences")
+		return err
+	}
+	for _, c := range changes {
+		if _, err := fmt.Fprintf(w, "%s %s %s/%s (%s)\n", changeMarks[c.Type], c.Kind, c.Configuration, c.Webhook, c.Type); err != nil {
+			return err
+		}
+		for _, f := range c.Fields {
+			if _, err := fmt.Fprintf(w, "    %s:\n      - %s\n      + %s\n", f.Field, orNone(f.Old), orNone(f.New)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,298 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"bytes"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+// webhook returns a validating webhook on pods served by policy/policy.
+func webhook(configuration, name string) printer.PrintItem {
+	return printer.PrintItem{
+		Kind: "Validating",
+		Name: configuration,
+		Webhook: printer.PrintWebhookItem{
+			Name:    name,
+			Service: printer.PrintServiceItem{Name: "policy", Namespace: "policy"},
+		},
+		ResourceModels: []printer.ResourceModel{{
+			APIGroups:  []string{""},
+			Operations: []string{"CREATE"},
+			Resources:  []string{"pods"},
+		}},
+		FailurePolicy:       "Fail",
+		CABundleFingerprint: "aa:bb",
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	port := int32(8443)
+	url := "https://policy.example.com"
+	timeout := int32(5)
+
+	tests := []struct {
+		name   string
+		modify func(item *printer.PrintItem)
+		want   []FieldChange
+	}{
+		{
+			name:   "unchanged",
+			modify: func(item *printer.PrintItem) {},
+		},
+		{
+			name:   "rules",
+			modify: func(item *printer.PrintItem) { item.ResourceModels[0].Operations = []string{"CREATE", "UPDATE"} },
+			want: []FieldChange{{
+				Field: "rules",
+				Old:   `[{"apiGroups":[""],"operations":["CREATE"],"resources":["pods"]}]`,
+				New:   `[{"apiGroups":[""],"operations":["CREATE","UPDATE"],"resources":["pods"]}]`,
+			}},
+		},
+		{
+			name: "discovered resources",
+			modify: func(item *printer.PrintItem) {
+				rm := &item.ResourceModels[0]
+				rm.Expanded, rm.Unserved, rm.Unknown = []string{"v1/pods"}, []string{"v1beta1/pods"}, []string{"v2/pods"}
+			},
+		},
+		{
+			name: "namespaceSelector",
+			modify: func(item *printer.PrintItem) {
+				item.NamespaceSelector = &metaV1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+			},
+			want: []FieldChange{{Field: "namespaceSelector", New: `{"matchLabels":{"team":"a"}}`}},
+		},
+		{
+			name: "matchConditions",
+			modify: func(item *printer.PrintItem) {
+				item.MatchConditions = []printer.PrintMatchConditionItem{{Name: "not-skipped", Expression: `!("skip" in object.metadata.labels)`}}
+			},
+			want: []FieldChange{{Field: "matchConditions", New: `[{"name":"not-skipped","expression":"!(\"skip\" in object.metadata.labels)"}]`}},
+		},
+		{
+			name:   "failurePolicy and timeout",
+			modify: func(item *printer.PrintItem) { item.FailurePolicy = "Ignore"; item.TimeoutSeconds = &timeout },
+			want: []FieldChange{
+				{Field: "failurePolicy", Old: "Fail", New: "Ignore"},
+				{Field: "timeoutSeconds", New: "5"},
+			},
+		},
+		{
+			name:   "caBundle",
+			modify: func(item *printer.PrintItem) { item.CABundleFingerprint = "cc:dd" },
+			want:   []FieldChange{{Field: "caBundle", Old: "aa:bb", New: "cc:dd"}},
+		},
+		{
+			name:   "caIssuer",
+			modify: func(item *printer.PrintItem) { item.CABundleIssuer = "CN=cert-manager" },
+			want:   []FieldChange{{Field: "caIssuer", New: "CN=cert-manager"}},
+		},
+		{
+			name:   "service port",
+			modify: func(item *printer.PrintItem) { item.Webhook.Service.Port = &port },
+			want:   []FieldChange{{Field: "target", Old: "policy/policy", New: "policy/policy:8443"}},
+		},
+		{
+			name: "service to url",
+			modify: func(item *printer.PrintItem) {
+				item.Webhook.Service = printer.PrintServiceItem{}
+				item.Webhook.URL = &url
+			},
+			want: []FieldChange{{Field: "target", Old: "policy/policy", New: url}},
+		},
+		{
+			name: "webhook to policy",
+			modify: func(item *printer.PrintItem) {
+				item.Webhook.Service = printer.PrintServiceItem{}
+				item.Policy = &printer.PrintPolicyItem{Validations: []printer.PrintValidationItem{{Expression: "false"}}}
+			},
+			want: []FieldChange{
+				{Field: "target", Old: "policy/policy", New: "in-process"},
+				{Field: "policy", New: `{"validations":[{"expression":"false"}]}`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := webhook("policy", "pods.policy.io")
+			tt.modify(&item)
+			changes := Diff(
+				&printer.PrintModel{Items: []printer.PrintItem{webhook("policy", "pods.policy.io")}},
+				&printer.PrintModel{Items: []printer.PrintItem{item}},
+				DiffFields)
+
+			if tt.want == nil {
+				if len(changes) != 0 {
+					t.Errorf("got changes %+v, want none", changes)
+				}
+				return
+			}
+			if len(changes) != 1 || changes[0].Type != Changed {
+				t.Fatalf("got changes %+v, want one changed webhook", changes)
+			}
+			if !reflect.DeepEqual(changes[0].Fields, tt.want) {
+				t.Errorf("got %+v, want %+v", changes[0].Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffPolicy(t *testing.T) {
+	policy := func(actions string, namespaces ...string) printer.PrintItem {
+		item := webhook("replicas", "replicas")
+		item.Kind, item.Webhook.Service = "Policy", printer.PrintServiceItem{}
+		item.Policy = &printer.PrintPolicyItem{Bindings: []printer.PrintPolicyBindingItem{{
+			Name:              "replicas",
+			ValidationActions: []string{actions},
+			ActiveNamespaces:  namespaces,
+		}}}
+		return item
+	}
+
+	tests := []struct {
+		name string
+		old  printer.PrintItem
+		new  printer.PrintItem
+		want []FieldChange
+	}{
+		{
+			name: "binding namespaces",
+			old:  policy("Deny", "default"),
+			new:  policy("Deny", "default", "payments"),
+		},
+		{
+			name: "binding actions",
+			old:  policy("Deny", "default"),
+			new:  policy("Warn", "default", "payments"),
+			want: []FieldChange{{
+				Field: "policy",
+				Old:   `{"bindings":[{"name":"replicas","validationActions":["Deny"],"activeNamespaces":null}]}`,
+				New:   `{"bindings":[{"name":"replicas","validationActions":["Warn"],"activeNamespaces":null}]}`,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Diff(
+				&printer.PrintModel{Items: []printer.PrintItem{tt.old}},
+				&printer.PrintModel{Items: []printer.PrintItem{tt.new}},
+				DiffFields)
+
+			if tt.want == nil {
+				if len(changes) != 0 {
+					t.Errorf("got changes %+v, want none", changes)
+				}
+				return
+			}
+			if len(changes) != 1 || !reflect.DeepEqual(changes[0].Fields, tt.want) {
+				t.Errorf("got %+v, want %+v", changes, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffMatchConditionErrors(t *testing.T) {
+	item := func(compileError string) printer.PrintItem {
+		i := webhook("policy", "pods.policy.io")
+		i.MatchConditions = []printer.PrintMatchConditionItem{{Name: "quota", Expression: `quantity("1Gi").isInteger()`, Error: compileError}}
+		return i
+	}
+
+	// an expression a former release could not compile is unchanged
+	changes := Diff(
+		&printer.PrintModel{Items: []printer.PrintItem{item("undeclared reference to 'quantity'")}},
+		&printer.PrintModel{Items: []printer.PrintItem{item("")}},
+		DiffFields)
+	if len(changes) != 0 {
+		t.Errorf("got changes %+v, want none", changes)
+	}
+}
+
+func TestDiffAddedRemoved(t *testing.T) {
+	old := &printer.PrintModel{Items: []printer.PrintItem{
+		webhook("policy", "pods.policy.io"),
+		webhook("legacy", "pods.legacy.io"),
+	}}
+	new := &printer.PrintModel{Items: []printer.PrintItem{
+		webhook("policy", "pods.policy.io"),
+		webhook("policy", "deployments.policy.io"),
+	}}
+	new.Items[1].Kind = "Mutating"
+
+	want := []Change{
+		{Type: Added, Kind: "Mutating", Configuration: "policy", Webhook: "deployments.policy.io"},
+		{Type: Removed, Kind: "Validating", Configuration: "legacy", Webhook: "pods.legacy.io"},
+	}
+	changes := Diff(old, new, DiffFields)
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("got %+v, want %+v", changes, want)
+	}
+
+	out := &bytes.Buffer{}
+	if err := WriteChanges(out, changes); err != nil {
+		t.Fatal(err)
+	}
+	wantOut := "+ Mutating policy/deployments.policy.io (added)\n- Validating legacy/pods.legacy.io (removed)\n"
+	if out.String() != wantOut {
+		t.Errorf("got\n%s\nwant\n%s", out, wantOut)
+	}
+}
+
+func TestWithout(t *testing.T) {
+	item := webhook("policy", "pods.policy.io")
+	changed := webhook("policy", "pods.policy.io")
+	changed.CABundleFingerprint = "cc:dd"
+	changed.FailurePolicy = "Ignore"
+
+	changes := Diff(
+		&printer.PrintModel{Items: []printer.PrintItem{item}},
+		&printer.PrintModel{Items: []printer.PrintItem{changed}},
+		Without(DiffFields, "caBundle"))
+	want := []FieldChange{{Field: "failurePolicy", Old: "Fail", New: "Ignore"}}
+	if len(changes) != 1 || !reflect.DeepEqual(changes[0].Fields, want) {
+		t.Errorf("got %+v, want only the failurePolicy to differ", changes)
+	}
+}
+
+func TestWriteChanges(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := WriteChanges(out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "No differences\n" {
+		t.Errorf("got %q, want No differences", out)
+	}
+
+	out.Reset()
+	err := WriteChanges(out, []Change{{
+		Type: Changed, Kind: "Validating", Configuration: "policy", Webhook: "pods.policy.io",
+		Fields: []FieldChange{{Field: "timeoutSeconds", New: "5"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "~ Validating policy/pods.policy.io (changed)\n    timeoutSeconds:\n      - <none>\n      + 5\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out, want)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/k8s"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"io/ioutil"
+	"path/filepath"
+	"sigs.k8s.io/yaml"
+	"strings"
+	"time"
+)
+
+// Snapshot is the analysed model of a cluster's webhooks at a point in
+// time, together with the raw configurations it was built from.
+type Snapshot struct {
+	Taken time.Time `json:"taken"`
+	// Filter is the name of the webhook configurations the snapshot is
+	// limited to, empty when it holds all of them.
+	Filter         string              `json:"filter,omitempty"`
+	Model          *printer.PrintModel `json:"model"`
+	Configurations *k8s.Configurations `json:"configurations"`
+}
+
+// New constructs a new Snapshot of the given model and configurations
+// taken now
+func New(model *printer.PrintModel, configurations *k8s.Configurations) *Snapshot {
+	return &Snapshot{
+		Taken:          time.Now().UTC(),
+		Model:          model,
+		Configurations: configurations,
+	}
+}
+
+// Save writes the Snapshot to the given path, as YAML when its extension
+// is .yaml or .yml and as JSON otherwise.
+func (s *Snapshot) Save(path string) error {
+	var data []byte
+	var err error
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(s)
+	default:
+		data, err = json.MarshalIndent(s, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Load reads a Snapshot saved as either JSON or YAML.
+func Load(path string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Snapshot{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %v", path, err)
+	}
+	if s.Model == nil {
+		return nil, fmt.Errorf("invalid snapshot %s: no model", path)
+	}
+	return s, nil
+}
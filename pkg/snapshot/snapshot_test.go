@@ -0,0 +1,64 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	model := &printer.PrintModel{Items: []printer.PrintItem{webhook("policy", "pods.policy.io")}}
+
+	for _, name := range []string{"snapshot.json", "snapshot.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := New(model, nil).Save(path); err != nil {
+				t.Fatal(err)
+			}
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if json := strings.HasPrefix(string(data), "{"); json != strings.HasSuffix(name, ".json") {
+				t.Errorf("%s is saved in the wrong format:\n%s", name, data)
+			}
+
+			s, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changes := Diff(model, s.Model, DiffFields); len(changes) != 0 {
+				t.Errorf("loaded model differs: %+v", changes)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.yaml")
+	if err := ioutil.WriteFile(path, []byte("taken: 2020-01-01T00:00:00Z\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "no model") {
+		t.Errorf("error = %v, want a snapshot without a model", err)
+	}
+}
@@ -0,0 +1,469 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tui is an interactive terminal browser of the webhooks of a
+// cluster, drawn with plain ANSI escape sequences.
+package tui
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/k8s"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"golang.org/x/term"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// State is what the Browser shows, loaded again on every refresh.
+type State struct {
+	Model          *printer.PrintModel
+	Configurations *k8s.Configurations
+	// Namespaces are the namespaces of the namespace view.
+	Namespaces []string
+	// Resources are the API resources of the resource view.
+	Resources []printer.APIResource
+	// Warnings tell what could not be loaded and is left out.
+	Warnings []string
+}
+
+// Loader loads the State of the cluster.
+type Loader func() (*State, error)
+
+type view int
+
+const (
+	webhookView view = iota
+	namespaceView
+	resourceView
+)
+
+var viewNames = []string{"Webhooks", "Namespaces", "Resources"}
+
+const help = "j/k move  J/K scroll  / filter  tab view  N/R namespaces/resources of webhook  y copy  r refresh  q quit"
+
+// Browser lists the webhooks, namespaces or resources of the cluster on
+// the left and the details of the selected one on the right.
+type Browser struct {
+	in      *os.File
+	out     *os.File
+	load    Loader
+	refresh time.Duration
+	// opts are the printer options the details are described with.
+	opts *printer.Options
+	// separator and ellipsis are drawn between the panes and at the end
+	// of truncated lines.
+	separator string
+	ellipsis  string
+
+	state    *State
+	loadedAt time.Time
+	loading  bool
+	err      error
+	// cache holds the entries of every view built since the State was
+	// loaded, which are filtered on every key.
+	cache map[view][]entry
+
+	view     view
+	filter   string
+	editing  bool
+	selected int
+	offset   int
+	scroll   int
+	status   string
+	width    int
+	height   int
+}
+
+type loadResult struct {
+	state *State
+	err   error
+}
+
+// NewBrowser constructs a new Browser reading keys from in and drawing on
+// out, both of which must be terminals. The State is loaded again every
+// refresh interval, never when it is zero. Colours and glyphs follow the
+// given printer options.
+func NewBrowser(in, out *os.File, load Loader, refresh time.Duration, opts *printer.Options) *Browser {
+	b := &Browser{
+		in:        in,
+		out:       out,
+		load:      load,
+		refresh:   refresh,
+		opts:      opts,
+		separator: "│",
+		ellipsis:  "…",
+		width:     80,
+		height:    24,
+	}
+	if opts.ASCII {
+		b.separator, b.ellipsis = "|", "..."
+	}
+	return b
+}
+
+// Run draws the Browser until it is quit.
+func (b *Browser) Run() error {
+	fd := int(b.in.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(b.out.Fd())) {
+		return errors.New("the interactive mode needs a terminal")
+	}
+
+	old, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, old)
+
+	// switch to the alternate screen and hide the cursor until quit
+	fmt.Fprint(b.out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(b.out, "\x1b[?25h\x1b[?1049l")
+
+	keys := make(chan string)
+	go readKeys(b.in, keys)
+
+	loaded := make(chan loadResult, 1)
+	b.startLoad(loaded)
+
+	var tick <-chan time.Time
+	if b.refresh > 0 {
+		ticker := time.NewTicker(b.refresh)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	// terminals are polled for their size, SIGWINCH is not portable
+	resize := time.NewTicker(250 * time.Millisecond)
+	defer resize.Stop()
+
+	b.resized()
+	b.render()
+	for {
+		select {
+		case key, ok := <-keys:
+			if !ok || b.handle(key, loaded) {
+				return nil
+			}
+		case result := <-loaded:
+			b.loading = false
+			b.err = result.err
+			if result.err == nil {
+				b.state, b.loadedAt = result.state, time.Now()
+				b.cache = nil
+				if len(result.state.Warnings) > 0 {
+					b.status = "warning: " + strings.Join(result.state.Warnings, "; ")
+				}
+			}
+		case <-tick:
+			b.startLoad(loaded)
+		case <-resize.C:
+			if !b.resized() {
+				continue
+			}
+		}
+		b.render()
+	}
+}
+
+func (b *Browser) startLoad(loaded chan<- loadResult) {
+	if b.loading {
+		return
+	}
+	b.loading = true
+	go func() {
+		state, err := b.load()
+		loaded <- loadResult{state: state, err: err}
+	}()
+}
+
+// resized updates the size of the terminal and tells whether it changed.
+func (b *Browser) resized() bool {
+	width, height, err := term.GetSize(int(b.out.Fd()))
+	if err != nil || (width == b.width && height == b.height) {
+		return false
+	}
+	b.width, b.height = width, height
+	return true
+}
+
+// handle handles a key and tells whether the Browser is to quit.
+func (b *Browser) handle(key string, loaded chan<- loadResult) bool {
+	b.status = ""
+
+	if b.editing {
+		switch key {
+		case "ctrl-c":
+			return true
+		case "enter":
+			b.editing = false
+		case "esc":
+			b.editing, b.filter = false, ""
+		case "backspace":
+			if _, size := utf8.DecodeLastRuneInString(b.filter); size > 0 {
+				b.filter = b.filter[:len(b.filter)-size]
+			}
+		default:
+			if utf8.RuneCountInString(key) == 1 {
+				b.filter += key
+			}
+		}
+		b.selected, b.offset, b.scroll = 0, 0, 0
+		return false
+	}
+
+	page := b.height - 3
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "up", "k":
+		b.move(-1)
+	case "down", "j":
+		b.move(1)
+	case "pgup":
+		b.move(-page)
+	case "pgdown":
+		b.move(page)
+	case "home", "g":
+		b.move(-b.selected)
+	case "end", "G":
+		b.move(len(b.entries()))
+	case "K", "left":
+		if b.scroll > 0 {
+			b.scroll--
+		}
+	case "J", "right":
+		b.scroll++
+	case "/":
+		b.editing = true
+	case "esc":
+		b.filter = ""
+		b.selected, b.offset, b.scroll = 0, 0, 0
+	case "tab":
+		b.show((b.view+1)%view(len(viewNames)), "")
+	case "1", "2", "3":
+		b.show(view(key[0]-'1'), "")
+	case "N", "R":
+		entries := b.entries()
+		if b.view != webhookView || len(entries) == 0 {
+			break
+		}
+		target := namespaceView
+		if key == "R" {
+			target = resourceView
+		}
+		b.show(target, entries[b.selected].ref)
+	case "r":
+		b.startLoad(loaded)
+	case "y":
+		b.copy()
+	}
+	return false
+}
+
+func (b *Browser) move(delta int) {
+	b.selected += delta
+	b.scroll = 0
+}
+
+// show switches to the given view, filtered by the given filter.
+func (b *Browser) show(v view, filter string) {
+	b.view, b.filter = v, filter
+	b.selected, b.offset, b.scroll = 0, 0, 0
+}
+
+// copy copies the YAML of the selected entry to the clipboard through an
+// OSC 52 sequence, which most terminals and tmux support.
+func (b *Browser) copy() {
+	entries := b.entries()
+	if len(entries) == 0 {
+		return
+	}
+	data, err := entries[b.selected].yaml()
+	if err != nil {
+		b.status = "copy failed: " + err.Error()
+		return
+	}
+	fmt.Fprintf(b.out, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString(data))
+	b.status = "copied " + entries[b.selected].title + " to the clipboard"
+}
+
+// entries returns the entries of the current view matching the filter.
+func (b *Browser) entries() []entry {
+	if b.state == nil || b.state.Model == nil {
+		return nil
+	}
+
+	all, ok := b.cache[b.view]
+	if !ok {
+		switch b.view {
+		case webhookView:
+			all = webhookEntries(b.state, b.opts)
+		case namespaceView:
+			all = namespaceEntries(b.state)
+		case resourceView:
+			all = resourceEntries(b.state)
+		}
+		if b.cache == nil {
+			b.cache = map[view][]entry{}
+		}
+		b.cache[b.view] = all
+	}
+	if b.filter == "" {
+		return all
+	}
+
+	var entries []entry
+	for _, e := range all {
+		if e.matches(b.filter) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func (b *Browser) render() {
+	entries := b.entries()
+	bodyHeight := b.height - 2
+	if bodyHeight < 1 {
+		bodyHeight = 1
+	}
+
+	if b.selected >= len(entries) {
+		b.selected = len(entries) - 1
+	}
+	if b.selected < 0 {
+		b.selected = 0
+	}
+	if b.selected < b.offset {
+		b.offset = b.selected
+	}
+	if b.selected >= b.offset+bodyHeight {
+		b.offset = b.selected - bodyHeight + 1
+	}
+
+	var details []string
+	if len(entries) > 0 {
+		details = entries[b.selected].details
+		if b.scroll > len(details)-1 {
+			b.scroll = len(details) - 1
+		}
+		if b.scroll > 0 {
+			details = details[b.scroll:]
+		}
+	}
+
+	leftWidth := b.width * 2 / 5
+	rightWidth := b.width - leftWidth - 1
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H")
+	sb.WriteString(b.header())
+
+	for row := 0; row < bodyHeight; row++ {
+		sb.WriteString("\r\n")
+
+		left := ""
+		i := b.offset + row
+		switch {
+		case i < len(entries):
+			left = b.fit(" "+entries[i].title, leftWidth)
+			if i == b.selected {
+				left = "\x1b[7m" + left + "\x1b[0m"
+			} else if entries[i].warning {
+				left = b.style("33", left)
+			}
+		case row == 0 && b.state == nil:
+			left = b.fit(" Loading...", leftWidth)
+		case row == 0:
+			left = b.fit(" No matches", leftWidth)
+		default:
+			left = b.fit("", leftWidth)
+		}
+
+		right := ""
+		if row < len(details) {
+			right = details[row]
+		}
+		sb.WriteString(left + b.separator + b.fit(" "+right, rightWidth))
+	}
+
+	sb.WriteString("\r\n")
+	status := b.status
+	switch {
+	case b.editing:
+		status = "filter: " + b.filter + "_"
+	case b.err != nil:
+		status = "error: " + b.err.Error()
+	case status == "":
+		status = help
+	}
+	status = b.fit(status, b.width)
+	if b.err != nil && !b.editing {
+		status = b.style("31", status)
+	}
+	sb.WriteString(status)
+
+	fmt.Fprint(b.out, sb.String())
+}
+
+func (b *Browser) header() string {
+	var tabs []string
+	for i, name := range viewNames {
+		tab := fmt.Sprintf(" %d %s ", i+1, name)
+		if view(i) == b.view {
+			tab = "\x1b[7m" + tab + "\x1b[0m"
+		}
+		tabs = append(tabs, tab)
+	}
+
+	info := ""
+	if b.filter != "" && !b.editing {
+		info += "filter: " + b.filter + "  "
+	}
+	switch {
+	case b.loading:
+		info += "refreshing..."
+	case !b.loadedAt.IsZero():
+		info += "refreshed " + b.loadedAt.Format("15:04:05")
+	}
+	return " view-webhook " + strings.Join(tabs, "") + "  " + info + "\x1b[K"
+}
+
+func (b *Browser) style(code, s string) string {
+	if b.opts.NoColor {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// fit truncates or pads the given text to the given width in runes.
+func (b *Browser) fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		cut := width - utf8.RuneCountInString(b.ellipsis)
+		if cut < 0 {
+			return string(runes[:width])
+		}
+		return string(runes[:cut]) + b.ellipsis
+	}
+	return s + strings.Repeat(" ", width-n)
+}
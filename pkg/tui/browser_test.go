@@ -0,0 +1,196 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tui
+
+import (
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"strings"
+	"testing"
+)
+
+// testState has a mutating webhook on pods in default, a validating
+// webhook whose service is missing and a policy on deployments.
+func testState() *State {
+	url := "https://injector.example.com"
+	pods := printer.ResourceModel{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}, Operations: []string{"CREATE"}, Scope: "*"}
+	deployments := printer.ResourceModel{APIGroups: []string{"apps"}, APIVersions: []string{"v1"}, Resources: []string{"deployments"}, Operations: []string{"UPDATE"}, Scope: "*"}
+	return &State{
+		Model: &printer.PrintModel{Items: []printer.PrintItem{
+			{Kind: "Mutating", Name: "injector", Webhook: printer.PrintWebhookItem{Name: "pods.injector.io", URL: &url}, ResourceModels: []printer.ResourceModel{pods}, ActiveNamespaces: []string{"default"}, FailurePolicy: "Fail"},
+			{Kind: "Validating", Name: "gatekeeper", Webhook: printer.PrintWebhookItem{Name: "validation.gatekeeper.sh", Service: printer.PrintServiceItem{Name: "gatekeeper", Namespace: "gatekeeper-system"}}, ResourceModels: []printer.ResourceModel{pods}, FailurePolicy: "Ignore"},
+			{Kind: "Validating", Name: "replicas", Policy: &printer.PrintPolicyItem{}, ResourceModels: []printer.ResourceModel{deployments}, ActiveNamespaces: []string{"default", "payments"}},
+		}},
+		Namespaces: []string{"default", "payments"},
+		Resources: []printer.APIResource{
+			{Version: "v1", Resource: "pods", Namespaced: true, Verbs: []string{"create"}},
+			{Group: "apps", Version: "v1", Resource: "deployments", Namespaced: true, Verbs: []string{"create", "update"}},
+		},
+	}
+}
+
+// titles returns the titles of the entries the Browser shows, the
+// selected one marked with a ">" and warnings with a "!".
+func titles(b *Browser) string {
+	var result []string
+	for i, e := range b.entries() {
+		title := e.title
+		if e.warning {
+			title = "!" + title
+		}
+		if i == b.selected {
+			title = ">" + title
+		}
+		result = append(result, title)
+	}
+	return strings.Join(result, ", ")
+}
+
+func TestBrowserHandle(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		{
+			name: "webhooks",
+			want: ">M injector/pods.injector.io, !V gatekeeper/validation.gatekeeper.sh, P replicas",
+		},
+		{
+			name: "move",
+			keys: []string{"j", "down", "k"},
+			want: "M injector/pods.injector.io, >!V gatekeeper/validation.gatekeeper.sh, P replicas",
+		},
+		{
+			name: "filter",
+			keys: []string{"/", "g", "a", "x", "backspace", "t", "enter"},
+			want: ">!V gatekeeper/validation.gatekeeper.sh",
+		},
+		{
+			name: "filter cancelled",
+			keys: []string{"/", "g", "a", "esc"},
+			want: ">M injector/pods.injector.io, !V gatekeeper/validation.gatekeeper.sh, P replicas",
+		},
+		{
+			name: "namespaces",
+			keys: []string{"tab"},
+			want: ">default (2), payments (1)",
+		},
+		{
+			name: "resources",
+			keys: []string{"3"},
+			want: ">v1 pods, !apps/v1 deployments",
+		},
+		{
+			name: "namespaces of a webhook",
+			keys: []string{"G", "N"},
+			want: ">default (2), payments (1)",
+		},
+		{
+			name: "resources of a webhook",
+			keys: []string{"N", "1", "R"},
+			want: ">v1 pods",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBrowser(nil, nil, nil, 0, printer.NewOptions())
+			b.state = testState()
+			for _, key := range tt.keys {
+				if b.handle(key, nil) {
+					t.Fatalf("quit on %q", key)
+				}
+				b.selected = clamp(b.selected, len(b.entries()))
+			}
+			if got := titles(b); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	b := NewBrowser(nil, nil, nil, 0, printer.NewOptions())
+	if !b.handle("q", nil) {
+		t.Error("q did not quit")
+	}
+}
+
+// clamp keeps the selection within the entries as render does.
+func clamp(selected, n int) int {
+	if selected >= n {
+		selected = n - 1
+	}
+	if selected < 0 {
+		selected = 0
+	}
+	return selected
+}
+
+func TestDetails(t *testing.T) {
+	opts := printer.NewOptions()
+	opts.NoColor = true
+	lines := strings.Join(details(testState().Model.Items[1], opts), "\n")
+	for _, want := range []string{
+		"Kind:  ValidatingWebhookConfiguration",
+		"  validation.gatekeeper.sh:",
+		"    Failure Policy:             Ignore",
+		"    Active Namespaces:          <none>",
+		"      Service:  gatekeeper-system/gatekeeper",
+		"      Status:   service not found",
+		"    CA Bundle:  <none>",
+	} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("details do not contain %q:\n%s", want, lines)
+		}
+	}
+}
+
+func TestEntriesCache(t *testing.T) {
+	b := NewBrowser(nil, nil, nil, 0, printer.NewOptions())
+	b.state = testState()
+	want := titles(b)
+
+	// the entries are built once per loaded State
+	b.state.Model.Items = b.state.Model.Items[:1]
+	if got := titles(b); got != want {
+		t.Errorf("got %q, want the cached %q", got, want)
+	}
+	b.cache = nil
+	if got := titles(b); got == want {
+		t.Errorf("got the cached %q after the State was loaded again", got)
+	}
+}
+
+func TestFit(t *testing.T) {
+	for _, tt := range []struct {
+		s     string
+		width int
+		ascii bool
+		want  string
+	}{
+		{s: "pods", width: 6, want: "pods  "},
+		{s: "deployments", width: 6, want: "deplo…"},
+		{s: "deployments", width: 6, ascii: true, want: "dep..."},
+		{s: "deployments", width: 2, ascii: true, want: "de"},
+		{s: "pods", width: 0, want: ""},
+	} {
+		opts := printer.NewOptions()
+		opts.ASCII = tt.ascii
+		if got := NewBrowser(nil, nil, nil, 0, opts).fit(tt.s, tt.width); got != tt.want {
+			t.Errorf("fit(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/match"
+	"github.com/Trendyol/kubectl-view-webhook/pkg/printer"
+	"sigs.k8s.io/yaml"
+	"strings"
+)
+
+// entry is a line of the list on the left and the details shown on the
+// right when it is selected.
+type entry struct {
+	title   string
+	details []string
+	// warning highlights entries with findings or missing coverage.
+	warning bool
+	// ref is the name the namespace and resource views are filtered by
+	// when jumping to them from the entry.
+	ref string
+	// object is copied as YAML, the details are copied when it is nil.
+	object interface{}
+}
+
+// matches tells whether the entry's title or details contain the filter,
+// case insensitively.
+func (e entry) matches(filter string) bool {
+	filter = strings.ToLower(filter)
+	if strings.Contains(strings.ToLower(e.title), filter) {
+		return true
+	}
+	for _, line := range e.details {
+		if strings.Contains(strings.ToLower(line), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// yaml returns the entry's object as YAML, or its details.
+func (e entry) yaml() ([]byte, error) {
+	if e.object == nil {
+		return []byte(strings.Join(e.details, "\n") + "\n"), nil
+	}
+	return yaml.Marshal(e.object)
+}
+
+func webhookEntries(state *State, opts *printer.Options) []entry {
+	var entries []entry
+	for _, item := range state.Model.Items {
+		e := entry{
+			title:   fmt.Sprintf("%s %s/%s", kindMark(item), item.Name, item.Webhook.Name),
+			details: details(item, opts),
+			warning: len(item.Findings) > 0 || item.Failures != nil || (item.Policy == nil && item.Webhook.URL == nil && !item.Webhook.Service.Found),
+			ref:     item.Name + "/" + item.Webhook.Name,
+		}
+		if item.Policy != nil {
+			e.title = fmt.Sprintf("%s %s", kindMark(item), item.Name)
+			e.ref = item.Name
+		}
+		if state.Configurations != nil {
+			e.object = state.Configurations.Object(item)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func namespaceEntries(state *State) []entry {
+	var entries []entry
+	for _, ns := range printer.NewNamespaceModel(state.Model, state.Namespaces).Items {
+		e := entry{title: fmt.Sprintf("%s (%d)", ns.Namespace, len(ns.Webhooks)), ref: ns.Namespace}
+		for _, wh := range ns.Webhooks {
+			e.details = append(e.details, fmt.Sprintf("%s %s/%s (%s)", wh.Kind, wh.Name, wh.Webhook, orNone(wh.FailurePolicy)))
+			for _, rm := range wh.ResourceModels {
+				e.details = append(e.details, "  "+rule(rm))
+			}
+		}
+		if len(ns.Webhooks) == 0 {
+			e.details = []string{"No webhook or policy intercepts requests in this namespace"}
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func resourceEntries(state *State) []entry {
+	var entries []entry
+	for _, item := range match.Resources(state.Model, state.Resources, false).Items {
+		title := fmt.Sprintf("%s %s", item.GroupVersion(), item.Resource)
+		if len(entries) == 0 || entries[len(entries)-1].title != title {
+			entries = append(entries, entry{title: title, ref: item.Resource})
+		}
+		e := &entries[len(entries)-1]
+
+		e.details = append(e.details, item.Operation)
+		for _, name := range item.Mutating {
+			e.details = append(e.details, "  mutated by "+name)
+		}
+		for _, name := range item.Validating {
+			e.details = append(e.details, "  validated by "+name)
+		}
+		if len(item.Validating) == 0 {
+			e.details = append(e.details, "  not validated")
+			e.warning = true
+		}
+	}
+	return entries
+}
+
+// details describes every field of the given item, one per line, the way
+// the describe output does with the given options.
+func details(item printer.PrintItem, opts *printer.Options) []string {
+	var buf bytes.Buffer
+	describe := *opts
+	describe.Format = "describe"
+	if err := printer.NewPrinter(&buf, &describe).Print(&printer.PrintModel{Items: []printer.PrintItem{item}}); err != nil {
+		return []string{err.Error()}
+	}
+	return strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+}
+
+// rule formats a rule as its operations, API versions and resources.
+func rule(rm printer.ResourceModel) string {
+	var groupVersions []string
+	for _, g := range rm.APIGroups {
+		for _, v := range rm.APIVersions {
+			if g == "" {
+				groupVersions = append(groupVersions, v)
+			} else {
+				groupVersions = append(groupVersions, g+"/"+v)
+			}
+		}
+	}
+	text := fmt.Sprintf("%s %s %s", strings.Join(rm.Operations, ","), strings.Join(groupVersions, ","), strings.Join(rm.Resources, ","))
+	if rm.Scope != "" && rm.Scope != "*" {
+		text += " (" + rm.Scope + ")"
+	}
+	return text
+}
+
+func kindMark(item printer.PrintItem) string {
+	switch {
+	case item.Policy != nil:
+		return "P"
+	case item.Kind == "Mutating":
+		return "M"
+	}
+	return "V"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
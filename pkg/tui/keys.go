@@ -0,0 +1,89 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// escapeKeys are the escape sequences of the keys the Browser handles,
+// without their leading ESC.
+var escapeKeys = map[string]string{
+	"[A": "up", "[B": "down", "[C": "right", "[D": "left",
+	"OA": "up", "OB": "down", "OC": "right", "OD": "left",
+	"[5~": "pgup", "[6~": "pgdown",
+	"[H": "home", "[F": "end", "[1~": "home", "[4~": "end",
+	"OH": "home", "OF": "end",
+}
+
+// readKeys sends the keys read from the given reader, which must be a
+// terminal in raw mode, until it fails.
+func readKeys(r io.Reader, keys chan<- string) {
+	defer close(keys)
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		for _, key := range parseKeys(buf[:n]) {
+			keys <- key
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// parseKeys splits the bytes of a read into key names: escape sequences
+// and control characters are named, printable characters are returned
+// as they are.
+func parseKeys(data []byte) []string {
+	var keys []string
+	for len(data) > 0 {
+		switch c := data[0]; {
+		case c == 0x1b:
+			key, size := "esc", 1
+			for seq, name := range escapeKeys {
+				if len(data) > len(seq) && string(data[1:1+len(seq)]) == seq {
+					key, size = name, 1+len(seq)
+					break
+				}
+			}
+			keys = append(keys, key)
+			data = data[size:]
+		case c == '\r' || c == '\n':
+			keys = append(keys, "enter")
+			data = data[1:]
+		case c == '\t':
+			keys = append(keys, "tab")
+			data = data[1:]
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, "backspace")
+			data = data[1:]
+		case c == 0x03:
+			keys = append(keys, "ctrl-c")
+			data = data[1:]
+		case c < 0x20:
+			// other control characters are ignored
+			data = data[1:]
+		default:
+			r, size := utf8.DecodeRune(data)
+			keys = append(keys, string(r))
+			data = data[size:]
+		}
+	}
+	return keys
+}
@@ -0,0 +1,42 @@
+/*
+Copyright © 2020 Trendyol Tech
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "letters", data: "jk/", want: "j k /"},
+		{name: "arrows", data: "\x1b[A\x1b[B\x1bOC\x1b[D", want: "up down right left"},
+		{name: "pages", data: "\x1b[5~\x1b[6~\x1b[1~\x1bOF", want: "pgup pgdown home end"},
+		{name: "escape alone", data: "\x1b", want: "esc"},
+		{name: "escape then letter", data: "\x1bq", want: "esc q"},
+		{name: "controls", data: "\r\n\t\x7f\x03\x01", want: "enter enter tab backspace ctrl-c"},
+		{name: "unicode", data: "é", want: "é"},
+	} {
+		if got := strings.Join(parseKeys([]byte(tt.data)), " "); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}